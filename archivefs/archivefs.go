@@ -0,0 +1,205 @@
+// Package archivefs provides a unified, read-only fs.FS view over plain
+// directories, .zip files, and .rpa files, including archives nested
+// inside one another - the common case for Ren'Py Android/web
+// distributions, which ship an .rpa inside a .zip. A path names a location
+// by joining directory/archive segments with "!/", e.g.
+// "build.zip!/game/game/archive.rpa!/script.rpyc".
+package archivefs
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/unren/unren-go/rpa"
+)
+
+// Open returns an fs.FS rooted at path, which may be a plain directory, a
+// single .zip/.rpa file, or a "!/"-joined chain descending into nested
+// archives. The returned io.Closer must be closed once fsys is no longer
+// needed; for a plain directory it is a no-op.
+func Open(path string) (fsys fs.FS, closer io.Closer, err error) {
+	segments := strings.Split(path, "!/")
+
+	root := segments[0]
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("archivefs: %w", err)
+	}
+
+	var closers multiCloser
+	if info.IsDir() {
+		fsys = os.DirFS(root)
+	} else {
+		fsys, closer, err = openContainerFile(root)
+		if err != nil {
+			return nil, nil, err
+		}
+		closers = append(closers, closer)
+	}
+
+	for _, seg := range segments[1:] {
+		next, nextCloser, err := descend(fsys, seg)
+		if err != nil {
+			closers.Close()
+			return nil, nil, err
+		}
+		fsys = next
+		if nextCloser != nil {
+			closers = append(closers, nextCloser)
+		}
+	}
+
+	return fsys, closers, nil
+}
+
+// Resolve opens the single file named by path - of the same "!/"-joined
+// form Open accepts - and returns a seekable reader over its full content
+// plus its size. A plain on-disk path (no "!/") is returned as the opened
+// *os.File. A file inside an archive is read fully into memory: fs.File
+// only guarantees io.Reader, not io.Seeker, so a seekable result for a file
+// living inside a nested archive can't be produced without buffering it.
+func Resolve(path string) (io.ReadSeeker, int64, error) {
+	segments := strings.Split(path, "!/")
+
+	if len(segments) == 1 {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, 0, fmt.Errorf("archivefs: %w", err)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, fmt.Errorf("archivefs: %w", err)
+		}
+		return f, info.Size(), nil
+	}
+
+	containerPath := strings.Join(segments[:len(segments)-1], "!/")
+	name := segments[len(segments)-1]
+
+	fsys, closer, err := Open(containerPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer closer.Close()
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, 0, fmt.Errorf("archivefs: %w", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, 0, fmt.Errorf("archivefs: %w", err)
+	}
+
+	return bytes.NewReader(data), int64(len(data)), nil
+}
+
+// openContainerFile opens path, an on-disk .zip or .rpa file, as an fs.FS.
+func openContainerFile(path string) (fs.FS, io.Closer, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("archivefs: %w", err)
+		}
+		return zr, zr, nil
+
+	case ".rpa":
+		a, err := rpa.Open(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("archivefs: %w", err)
+		}
+		return a.FS(), nopCloser{}, nil
+
+	default:
+		return nil, nil, fmt.Errorf("archivefs: unsupported container %q", path)
+	}
+}
+
+// descend resolves one "!/"-joined segment against fsys: a plain
+// subdirectory becomes an fs.Sub, while a path ending in .zip/.rpa is
+// opened as a nested archive. Nested archives are buffered into memory (or,
+// for .rpa, a temp file - rpa.Open needs a real path) since fsys may not
+// offer random access to the bytes of a file inside it.
+func descend(fsys fs.FS, seg string) (fs.FS, io.Closer, error) {
+	ext := strings.ToLower(filepath.Ext(seg))
+	if ext != ".zip" && ext != ".rpa" {
+		sub, err := fs.Sub(fsys, seg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("archivefs: %w", err)
+		}
+		return sub, nil, nil
+	}
+
+	f, err := fsys.Open(seg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("archivefs: %w", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("archivefs: %w", err)
+	}
+
+	switch ext {
+	case ".zip":
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("archivefs: %w", err)
+		}
+		return zr, nopCloser{}, nil
+
+	default: // ".rpa"
+		tmp, err := os.CreateTemp("", "archivefs-*.rpa")
+		if err != nil {
+			return nil, nil, fmt.Errorf("archivefs: %w", err)
+		}
+		if _, err := tmp.Write(data); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return nil, nil, fmt.Errorf("archivefs: %w", err)
+		}
+		tmp.Close()
+
+		a, err := rpa.Open(tmp.Name())
+		if err != nil {
+			os.Remove(tmp.Name())
+			return nil, nil, fmt.Errorf("archivefs: %w", err)
+		}
+		return a.FS(), tempFileCloser(tmp.Name()), nil
+	}
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// tempFileCloser removes the named temp file on Close, cleaning up the
+// scratch copy descend made of a nested .rpa.
+type tempFileCloser string
+
+func (t tempFileCloser) Close() error { return os.Remove(string(t)) }
+
+// multiCloser closes its closers in reverse (most-recently-opened first),
+// returning the first error encountered.
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var firstErr error
+	for i := len(m) - 1; i >= 0; i-- {
+		if err := m[i].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
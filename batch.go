@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/unren/unren-go/detector"
+	"github.com/unren/unren-go/utils"
+)
+
+// isBatchMode reports whether the given positional args should be treated
+// as a set of game roots/glob patterns rather than a single game directory:
+// either more than one was given, or the one given looks like a glob.
+func isBatchMode(args []string) bool {
+	if len(args) > 1 {
+		return true
+	}
+	if len(args) == 1 && strings.ContainsAny(args[0], "*?[") {
+		return true
+	}
+	return false
+}
+
+// expandHome resolves a leading "~" to the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// expandGameRoots resolves glob patterns to a deduplicated list of detected
+// game root directories. Each pattern is globbed first; every resulting
+// directory (or the pattern itself, if filepath.Glob found no matches) is
+// then walked looking for a detectable Ren'Py game, short-circuiting at the
+// first hit per subtree so we don't recurse into an already-detected game's
+// game/ folder.
+func expandGameRoots(patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var roots []string
+
+	for _, raw := range patterns {
+		pattern := expandHome(strings.Trim(raw, `"'`))
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			matches = []string{pattern}
+		}
+
+		for _, m := range matches {
+			discoverGames(m, seen, &roots)
+		}
+	}
+
+	return roots, nil
+}
+
+// discoverGames recursively looks for a Ren'Py game under root, recording
+// it in roots (deduplicated via seen) and not descending any further once
+// a game is detected at a given level.
+func discoverGames(root string, seen map[string]bool, roots *[]string) {
+	info, err := os.Stat(root)
+	if err != nil || !info.IsDir() {
+		return
+	}
+
+	if game, err := detector.DetectGame(root); err == nil {
+		if !seen[game.RootDir] {
+			seen[game.RootDir] = true
+			*roots = append(*roots, game.RootDir)
+		}
+		return
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			discoverGames(filepath.Join(root, entry.Name()), seen, roots)
+		}
+	}
+}
+
+// batchResult summarizes the outcome of processing one game in a batch run,
+// feeding the final results table.
+type batchResult struct {
+	Name         string
+	RenPyVersion int
+	RPACount     int
+	RPYCCount    int
+	Actions      []string
+	Err          error
+}
+
+// runBatchMode expands patterns to game roots, processes each with the
+// requested action flags, and prints the final summary table.
+func runBatchMode(patterns []string, jobs int, continueOnError bool, extract, decompile, console, quicksave, skip, rollback, all, clean bool, parallel int, shard string, verbose bool, keepTemp bool, tempBase string, allowSystemPython bool) {
+	roots, err := expandGameRoots(patterns)
+	if err != nil {
+		fmt.Printf("  ! %v\n", err)
+		return
+	}
+	if len(roots) == 0 {
+		fmt.Println("  No games found matching the given patterns.")
+		return
+	}
+
+	results := runBatch(roots, jobs, continueOnError, func(game *detector.GameInfo) []string {
+		return runActions(game, extract || all, decompile || all, console || all, quicksave || all, skip || all, rollback || all, clean, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython)
+	})
+
+	printBatchTable(results)
+}
+
+// runBatch detects and processes every game root, honoring continueOnError
+// and a bound of `jobs` concurrently-processed games. Game output is not
+// serialized across workers, so interleaved text is expected with jobs > 1.
+// Batch runs always print text: --format json/ndjson applies only to
+// single-game runs.
+func runBatch(roots []string, jobs int, continueOnError bool, process func(game *detector.GameInfo) []string) []batchResult {
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > len(roots) {
+		jobs = len(roots)
+	}
+
+	results := make([]batchResult, len(roots))
+	jobsCh := make(chan int)
+	var stop atomic.Bool
+
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobsCh {
+				if stop.Load() {
+					continue
+				}
+
+				root := roots[i]
+				game, err := detector.DetectGame(root)
+				if err != nil {
+					results[i] = batchResult{Name: filepath.Base(root), Err: err}
+					if !continueOnError {
+						stop.Store(true)
+					}
+					continue
+				}
+
+				fmt.Printf("\n==> %s\n", game.Name)
+				results[i] = batchResult{
+					Name:         game.Name,
+					RenPyVersion: game.RenPyVersion,
+					RPACount:     len(game.RPAFiles),
+					RPYCCount:    len(game.RPYCFiles),
+					Actions:      process(game),
+				}
+
+				if !continueOnError && actionsFailed(results[i].Actions) {
+					stop.Store(true)
+				}
+			}
+		}()
+	}
+
+	for i := range roots {
+		jobsCh <- i
+	}
+	close(jobsCh)
+	wg.Wait()
+
+	return results
+}
+
+// actionsFailed reports whether any of the per-action status strings
+// produced by runActions indicates a failure.
+func actionsFailed(actions []string) bool {
+	for _, a := range actions {
+		if strings.Contains(a, "failed") {
+			return true
+		}
+	}
+	return false
+}
+
+// runActions runs the requested action flags against game and returns a
+// human-readable status string per action performed, for the batch summary
+// table.
+func runActions(game *detector.GameInfo, extract, decompile, console, quicksave, skip, rollback, clean bool, parallel int, shard string, verbose bool, keepTemp bool, tempBase string, allowSystemPython bool) []string {
+	var actions []string
+
+	if extract {
+		handleExtractRPA(game, clean, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython)
+		actions = append(actions, fmt.Sprintf("extract: %d archive(s)", len(game.RPAFiles)))
+	}
+	if decompile {
+		handleDecompileRPYC(game, clean, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython)
+		actions = append(actions, fmt.Sprintf("decompile: %d file(s)", len(game.RPYCFiles)))
+	}
+	if console {
+		handleEnableConsole(game)
+		actions = append(actions, patchStatus(game, "console", "unren-dev.rpy"))
+	}
+	if quicksave {
+		handleEnableQuickSave(game)
+		actions = append(actions, patchStatus(game, "quicksave", "unren-quick.rpy"))
+	}
+	if skip {
+		handleEnableSkip(game)
+		actions = append(actions, patchStatus(game, "skip", "unren-skip.rpy"))
+	}
+	if rollback {
+		handleEnableRollback(game)
+		actions = append(actions, patchStatus(game, "rollback", "unren-rollback.rpy"))
+	}
+
+	return actions
+}
+
+// patchStatus checks whether a patch file was actually written, for the
+// batch table's per-action status column.
+func patchStatus(game *detector.GameInfo, label, filename string) string {
+	if utils.FileExists(filepath.Join(game.GameDir, filename)) {
+		return label + ": ok"
+	}
+	return label + ": failed"
+}
+
+// printBatchTable prints the final per-game summary after a batch run.
+func printBatchTable(results []batchResult) {
+	fmt.Println()
+	fmt.Println("  Batch Summary:")
+	fmt.Println("  --------------------------------------------------------------")
+	fmt.Printf("  %-30s %-6s %-5s %-5s %s\n", "Name", "RenPy", "RPA", "RPYC", "Status")
+	for _, res := range results {
+		if res.Err != nil {
+			fmt.Printf("  %-30s %-6s %-5s %-5s %s\n", res.Name, "-", "-", "-", "error: "+res.Err.Error())
+			continue
+		}
+
+		renpy := "?"
+		if res.RenPyVersion > 0 {
+			renpy = fmt.Sprintf("%d.x", res.RenPyVersion)
+		}
+		status := "ok"
+		if len(res.Actions) > 0 {
+			status = strings.Join(res.Actions, ", ")
+		}
+		fmt.Printf("  %-30s %-6s %-5d %-5d %s\n", res.Name, renpy, res.RPACount, res.RPYCCount, status)
+	}
+}
@@ -0,0 +1,379 @@
+// Package config loads user-defined defaults and per-game overrides for
+// UnRen-Go from a small TOML-like file format, so choices like custom
+// hotkeys or a preferred action set don't have to be re-typed as flags on
+// every run.
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Overrides holds the subset of settings that can be set globally or
+// scoped to a single game via a [[game]] block. Bool fields are pointers so
+// merging can tell "not set in this file" apart from "explicitly false".
+type Overrides struct {
+	Extract   *bool
+	Decompile *bool
+	Console   *bool
+	QuickSave *bool
+	Skip      *bool
+	Rollback  *bool
+	Clean     *bool
+
+	// QuickSaveKey and QuickLoadKey are the only hotkeys actually threaded
+	// through to patcher.Config today, since unren-quick.rpy.tmpl is the
+	// only embedded template that's parameterized. SkipKey, DevMenuKey, and
+	// RollbackKey are parsed and merged like the rest so `config show`
+	// reflects what the user asked for, but the console/skip/rollback
+	// patches remain fixed-hotkey until those templates gain placeholders.
+	QuickSaveKey string
+	SkipKey      string
+	DevMenuKey   string
+	RollbackKey  string
+	QuickLoadKey string
+
+	// PythonPath, if set, is passed to runner.NewRunner as
+	// PythonPathOverride, replacing the bundled interpreter search (and the
+	// AllowSystemPython PATH fallback) with this exact executable.
+	PythonPath string
+
+	// Templates maps an entry in files.RPYFiles (e.g. "unren-dev.rpy") to an
+	// on-disk replacement, letting a user swap an embedded template without
+	// recompiling.
+	Templates map[string]string
+}
+
+// GameOverride scopes an Overrides block to games whose directory matches
+// Match, a filepath.Match glob (e.g. "~/Games/JapaneseVN/*").
+type GameOverride struct {
+	Match string
+	Overrides
+}
+
+// Config is the fully merged configuration for one run: Overrides merged
+// from every config file found, plus every [[game]] block encountered
+// (already folded into Overrides by Load for the game directory it was
+// given, and kept here verbatim).
+type Config struct {
+	Overrides
+	Games []GameOverride
+}
+
+// Default returns the built-in defaults, matching patcher.DefaultConfig.
+func Default() *Config {
+	return &Config{
+		Overrides: Overrides{
+			QuickSaveKey: "K_F5",
+			QuickLoadKey: "K_F9",
+			Templates:    map[string]string{},
+		},
+	}
+}
+
+// searchPaths returns the three config file locations, in merge order
+// (later files win): $XDG_CONFIG_HOME/unren/config.toml, ./unren.toml, and
+// <gameDir>/.unrenrc.
+func searchPaths(gameDir string) []string {
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	if xdg == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			xdg = filepath.Join(home, ".config")
+		}
+	}
+
+	var paths []string
+	if xdg != "" {
+		paths = append(paths, filepath.Join(xdg, "unren", "config.toml"))
+	}
+	paths = append(paths, "unren.toml")
+	if gameDir != "" {
+		paths = append(paths, filepath.Join(gameDir, ".unrenrc"))
+	}
+	return paths
+}
+
+// Load reads and merges every config file in searchPaths(gameDir) that
+// exists, applies built-in defaults first, then any [[game]] override whose
+// Match glob matches gameDir. A missing file is not an error; a malformed
+// one is.
+func Load(gameDir string) (*Config, error) {
+	cfg := Default()
+
+	for _, path := range searchPaths(gameDir) {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		parsed, err := parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		mergeOverrides(&cfg.Overrides, parsed.Overrides)
+		for name, path := range parsed.Templates {
+			cfg.Templates[name] = path
+		}
+		cfg.Games = append(cfg.Games, parsed.Games...)
+	}
+
+	for _, g := range cfg.Games {
+		if matchGame(g.Match, gameDir) {
+			mergeOverrides(&cfg.Overrides, g.Overrides)
+		}
+	}
+
+	return cfg, nil
+}
+
+// matchGame reports whether gameDir matches the ~-expandable glob pattern.
+func matchGame(pattern, gameDir string) bool {
+	if pattern == "" || gameDir == "" {
+		return false
+	}
+	ok, err := filepath.Match(expandHome(pattern), gameDir)
+	return err == nil && ok
+}
+
+// expandHome resolves a leading "~" to the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// mergeOverrides copies every field src sets onto dst, leaving dst's
+// existing value in place wherever src leaves a field unset.
+func mergeOverrides(dst *Overrides, src Overrides) {
+	if src.Extract != nil {
+		dst.Extract = src.Extract
+	}
+	if src.Decompile != nil {
+		dst.Decompile = src.Decompile
+	}
+	if src.Console != nil {
+		dst.Console = src.Console
+	}
+	if src.QuickSave != nil {
+		dst.QuickSave = src.QuickSave
+	}
+	if src.Skip != nil {
+		dst.Skip = src.Skip
+	}
+	if src.Rollback != nil {
+		dst.Rollback = src.Rollback
+	}
+	if src.Clean != nil {
+		dst.Clean = src.Clean
+	}
+	if src.QuickSaveKey != "" {
+		dst.QuickSaveKey = src.QuickSaveKey
+	}
+	if src.QuickLoadKey != "" {
+		dst.QuickLoadKey = src.QuickLoadKey
+	}
+	if src.SkipKey != "" {
+		dst.SkipKey = src.SkipKey
+	}
+	if src.DevMenuKey != "" {
+		dst.DevMenuKey = src.DevMenuKey
+	}
+	if src.RollbackKey != "" {
+		dst.RollbackKey = src.RollbackKey
+	}
+	if src.PythonPath != "" {
+		dst.PythonPath = src.PythonPath
+	}
+}
+
+// defaultTOML is written by `unren config init`.
+const defaultTOML = `# UnRen-Go configuration.
+# Loaded from (in order, later overrides earlier):
+#   $XDG_CONFIG_HOME/unren/config.toml
+#   ./unren.toml
+#   <game_directory>/.unrenrc
+
+[actions]
+# extract = true
+# decompile = true
+# console = true
+# quicksave = true
+# skip = true
+# rollback = true
+# clean = false
+
+[keys]
+# quicksave_key = "K_F5"
+# quickload_key = "K_F9"
+
+[python]
+# interpreter = "/path/to/python3"
+
+[templates]
+# unren-dev.rpy = "/home/user/custom-dev.rpy"
+
+# [[game]]
+# match = "~/Games/JapaneseVN/*"
+# quickload_key = "K_F8"
+`
+
+// WriteDefault writes a commented default config file to path, failing if
+// one already exists there.
+func WriteDefault(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+	return os.WriteFile(path, []byte(defaultTOML), 0644)
+}
+
+// DefaultConfigPath returns the $XDG_CONFIG_HOME/unren/config.toml path
+// `unren config init` writes to when run with no explicit path.
+func DefaultConfigPath() (string, error) {
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	if xdg == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("could not determine home directory: %w", err)
+		}
+		xdg = filepath.Join(home, ".config")
+	}
+	return filepath.Join(xdg, "unren", "config.toml"), nil
+}
+
+// parsedFile is the result of parsing one config file, before merging.
+type parsedFile struct {
+	Overrides
+	Games []GameOverride
+}
+
+// parse reads UnRen's minimal TOML-like subset: "#" comments, "[section]"
+// and repeatable "[[game]]" headers, and flat "key = value" pairs (quoted
+// strings or bare true/false). There is no nesting beyond one section
+// level and no support for multi-line values or full TOML's other types -
+// this repo has no TOML dependency, so the format only covers what UnRen's
+// own config actually needs.
+func parse(data []byte) (*parsedFile, error) {
+	pf := &parsedFile{Overrides: Overrides{Templates: map[string]string{}}}
+
+	section := ""
+	var curGame *GameOverride
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[[") && strings.HasSuffix(line, "]]") {
+			name := strings.TrimSpace(line[2 : len(line)-2])
+			if name != "game" {
+				return nil, fmt.Errorf("unsupported array-of-tables [[%s]]", name)
+			}
+			pf.Games = append(pf.Games, GameOverride{})
+			curGame = &pf.Games[len(pf.Games)-1]
+			section = "game"
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			curGame = nil
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid line (expected key = value): %q", line)
+		}
+		key = strings.TrimSpace(key)
+		value = parseValue(strings.TrimSpace(value))
+
+		switch section {
+		case "templates":
+			pf.Templates[key] = value
+		case "game":
+			if key == "match" {
+				curGame.Match = value
+			} else if err := applyKey(&curGame.Overrides, key, value); err != nil {
+				return nil, err
+			}
+		default:
+			if err := applyKey(&pf.Overrides, key, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return pf, scanner.Err()
+}
+
+// parseValue strips surrounding quotes from a TOML-style string value,
+// leaving bare tokens like true/false untouched.
+func parseValue(raw string) string {
+	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
+		return raw[1 : len(raw)-1]
+	}
+	return raw
+}
+
+// applyKey sets the Overrides field named by key, recognized across
+// [actions], [keys], [python], and [[game]] blocks alike.
+func applyKey(ov *Overrides, key, value string) error {
+	switch key {
+	case "extract", "decompile", "console", "quicksave", "skip", "rollback", "clean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", key, value)
+		}
+		switch key {
+		case "extract":
+			ov.Extract = &b
+		case "decompile":
+			ov.Decompile = &b
+		case "console":
+			ov.Console = &b
+		case "quicksave":
+			ov.QuickSave = &b
+		case "skip":
+			ov.Skip = &b
+		case "rollback":
+			ov.Rollback = &b
+		case "clean":
+			ov.Clean = &b
+		}
+	case "quicksave_key":
+		ov.QuickSaveKey = value
+	case "quickload_key":
+		ov.QuickLoadKey = value
+	case "skip_key":
+		ov.SkipKey = value
+	case "devmenu_key":
+		ov.DevMenuKey = value
+	case "rollback_key":
+		ov.RollbackKey = value
+	case "interpreter":
+		ov.PythonPath = value
+	default:
+		return fmt.Errorf("unknown config key %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/unren/unren-go/config"
+)
+
+// applyConfigActionDefaults fills in any action flag the user didn't pass
+// explicitly (per setFlags, from flag.Visit) with the value configured in
+// cfg's [actions] section, if any. Flags passed on the command line always
+// win over config.
+func applyConfigActionDefaults(cfg *config.Config, setFlags map[string]bool, extract, decompile, console, quicksave, skip, rollback, clean *bool) {
+	apply := func(name string, dst *bool, val *bool) {
+		if !setFlags[name] && val != nil {
+			*dst = *val
+		}
+	}
+	apply("extract", extract, cfg.Extract)
+	apply("e", extract, cfg.Extract)
+	apply("decompile", decompile, cfg.Decompile)
+	apply("d", decompile, cfg.Decompile)
+	apply("console", console, cfg.Console)
+	apply("quicksave", quicksave, cfg.QuickSave)
+	apply("skip", skip, cfg.Skip)
+	apply("rollback", rollback, cfg.Rollback)
+	apply("clean", clean, cfg.Clean)
+	apply("c", clean, cfg.Clean)
+}
+
+// runConfigCommand dispatches `unren config init|show`.
+func runConfigCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "  ! config requires a subcommand: init, show")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "init":
+		runConfigInit(args[1:])
+	case "show":
+		runConfigShow(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "  ! unknown config subcommand %q (want init or show)\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func runConfigInit(args []string) {
+	fs := flag.NewFlagSet("config init", flag.ExitOnError)
+	fs.Parse(args)
+
+	path := ""
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	} else {
+		var err error
+		path, err = config.DefaultConfigPath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  ! %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := config.WriteDefault(path); err != nil {
+		fmt.Fprintf(os.Stderr, "  ! %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("  Wrote default config to %s\n", path)
+}
+
+func runConfigShow(args []string) {
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	forDir := fs.String("for", ".", "Game directory to resolve per-game overrides for")
+	fs.Parse(args)
+
+	cfg, err := config.Load(*forDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  ! %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("  Effective config for %s:\n", *forDir)
+	fmt.Printf("    quicksave_key: %s\n", cfg.QuickSaveKey)
+	fmt.Printf("    quickload_key: %s\n", cfg.QuickLoadKey)
+	if cfg.SkipKey != "" {
+		fmt.Printf("    skip_key:      %s\n", cfg.SkipKey)
+	}
+	if cfg.DevMenuKey != "" {
+		fmt.Printf("    devmenu_key:   %s\n", cfg.DevMenuKey)
+	}
+	if cfg.RollbackKey != "" {
+		fmt.Printf("    rollback_key:  %s\n", cfg.RollbackKey)
+	}
+	if cfg.PythonPath != "" {
+		fmt.Printf("    interpreter:   %s\n", cfg.PythonPath)
+	}
+	for name, path := range cfg.Templates {
+		fmt.Printf("    template %s -> %s\n", name, path)
+	}
+	for _, action := range []struct {
+		name string
+		val  *bool
+	}{
+		{"extract", cfg.Extract}, {"decompile", cfg.Decompile}, {"console", cfg.Console},
+		{"quicksave", cfg.QuickSave}, {"skip", cfg.Skip}, {"rollback", cfg.Rollback}, {"clean", cfg.Clean},
+	} {
+		if action.val != nil {
+			fmt.Printf("    %s: %v\n", action.name, *action.val)
+		}
+	}
+}
@@ -1,7 +1,8 @@
 package detector
 
 import (
-	"fmt"
+	"context"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -34,6 +35,12 @@ type GameInfo struct {
 // DetectGame attempts to detect a Ren'Py game from the given directory
 // It can be called from either the game root or the game/ subdirectory
 func DetectGame(dir string) (*GameInfo, error) {
+	return DetectGameCtx(context.Background(), dir)
+}
+
+// DetectGameCtx is DetectGame, checking ctx before each file-extension scan
+// so a large game's lib/ and game/ directory walks can be cancelled.
+func DetectGameCtx(ctx context.Context, dir string) (*GameInfo, error) {
 	absDir, err := filepath.Abs(dir)
 	if err != nil {
 		return nil, err
@@ -63,7 +70,6 @@ func DetectGame(dir string) (*GameInfo, error) {
 	} else {
 		// Try to find game/ in current directory
 		// Maybe we're in some other subdirectory
-		fmt.Printf("Detection: Failed to find game directory in %s\n", absDir)
 		return nil, &GameNotFoundError{Dir: absDir}
 	}
 
@@ -92,15 +98,154 @@ func DetectGame(dir string) (*GameInfo, error) {
 	// Detect Ren'Py version
 	info.RenPyVersion = detectRenPyVersion(info)
 
-	// Find RPA files
-	info.RPAFiles, _ = utils.FindFilesWithExtension(info.GameDir, ".rpa")
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Find RPA and RPYC files by walking the game directory as an fs.FS,
+	// the same code path findFilesWithExtensionFS uses for archive- and
+	// zip-backed fs.FS roots via DetectGameFS.
+	gameFS := os.DirFS(info.GameDir)
+
+	rpaFiles, err := findFilesWithExtensionFS(ctx, gameFS, ".rpa")
+	if err != nil && err != ctx.Err() {
+		return nil, err
+	}
+	info.RPAFiles = prefixPaths(info.GameDir, rpaFiles)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	// Find RPYC files
-	info.RPYCFiles, _ = utils.FindFilesWithExtension(info.GameDir, ".rpyc")
+	rpycFiles, err := findFilesWithExtensionFS(ctx, gameFS, ".rpyc")
+	if err != nil && err != ctx.Err() {
+		return nil, err
+	}
+	info.RPYCFiles = prefixPaths(info.GameDir, rpycFiles)
 
 	return info, nil
 }
 
+// prefixPaths rejoins the fs.FS-relative paths findFilesWithExtensionFS
+// returns with gameDir, restoring the real OS paths callers outside this
+// package expect in GameInfo.RPAFiles/RPYCFiles.
+func prefixPaths(gameDir string, relPaths []string) []string {
+	if relPaths == nil {
+		return nil
+	}
+	out := make([]string, len(relPaths))
+	for i, p := range relPaths {
+		out[i] = filepath.Join(gameDir, p)
+	}
+	return out
+}
+
+// DetectGameFS detects a Ren'Py game's layout from an arbitrary fs.FS -
+// an extracted directory, a mounted .zip/.rpa (see the archivefs package),
+// or anything else implementing fs.FS - rooted at either the game root or
+// its game/ subdirectory.
+//
+// Unlike DetectGame, the returned GameInfo's RootDir/GameDir/LibDir are
+// left empty: fs.FS has no notion of an absolute OS path, and lib/ and
+// renpy/ detection only looks inside fsys, so the macOS app-bundle and
+// sibling-lib-directory fallbacks DetectGame supports don't apply here.
+// RPAFiles/RPYCFiles are slash paths relative to the game/ directory within
+// fsys (or to fsys itself when it has no game/ directory), not OS paths.
+func DetectGameFS(fsys fs.FS) (*GameInfo, error) {
+	return DetectGameFSCtx(context.Background(), fsys)
+}
+
+// DetectGameFSCtx is DetectGameFS, checking ctx between file-extension
+// scans.
+func DetectGameFSCtx(ctx context.Context, fsys fs.FS) (*GameInfo, error) {
+	info := &GameInfo{}
+
+	gameFS := fsys
+	if fi, err := fs.Stat(fsys, "game"); err == nil && fi.IsDir() {
+		sub, err := fs.Sub(fsys, "game")
+		if err != nil {
+			return nil, err
+		}
+		gameFS = sub
+	} else if _, err := fs.Stat(fsys, "."); err != nil {
+		return nil, &GameNotFoundError{Dir: "<fs.FS>"}
+	}
+
+	if fi, err := fs.Stat(fsys, "lib"); err == nil && fi.IsDir() {
+		info.HasLib = true
+	}
+	if fi, err := fs.Stat(fsys, "renpy"); err == nil && fi.IsDir() {
+		info.HasRenPy = true
+	}
+
+	info.RenPyVersion = detectRenPyVersionFS(fsys, info)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rpaFiles, err := findFilesWithExtensionFS(ctx, gameFS, ".rpa")
+	if err != nil && err != ctx.Err() {
+		return nil, err
+	}
+	info.RPAFiles = rpaFiles
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rpycFiles, err := findFilesWithExtensionFS(ctx, gameFS, ".rpyc")
+	if err != nil && err != ctx.Err() {
+		return nil, err
+	}
+	info.RPYCFiles = rpycFiles
+
+	return info, nil
+}
+
+// detectRenPyVersionFS is detectRenPyVersion for an fs.FS game root.
+func detectRenPyVersionFS(fsys fs.FS, info *GameInfo) int {
+	if info.HasLib {
+		entries, err := fs.ReadDir(fsys, "lib")
+		if err == nil {
+			for _, entry := range entries {
+				name := strings.ToLower(entry.Name())
+				if strings.Contains(name, "py3") || strings.Contains(name, "python3") {
+					return 8
+				}
+				if strings.Contains(name, "py2") || strings.Contains(name, "python2") {
+					return 7
+				}
+			}
+		}
+	}
+
+	if fi, err := fs.Stat(fsys, "renpy/__pycache__"); err == nil && fi.IsDir() {
+		return 8
+	}
+
+	return 0
+}
+
+// findFilesWithExtensionFS walks fsys looking for files whose extension
+// matches ext (case-insensitive), checking ctx between directory entries.
+func findFilesWithExtensionFS(ctx context.Context, fsys fs.FS, ext string) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if !d.IsDir() && strings.EqualFold(filepath.Ext(p), ext) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
 // detectRenPyVersion attempts to detect the Ren'Py version
 func detectRenPyVersion(info *GameInfo) int {
 	// Check for Python version indicators in lib/ directory
@@ -22,9 +22,15 @@ var unrpycPy3 embed.FS
 //go:embed python/unrpyc_py2/*
 var unrpycPy2 embed.FS
 
-// GetRPYContent returns the content of a static RPY file.
-// For templated files, use GetRPYTemplated instead.
-func GetRPYContent(filename string) ([]byte, error) {
+// GetRPYContent returns the content of a static RPY file. If resolve is
+// non-nil and returns ok==true for filename, its content is used instead of
+// the embedded copy. For templated files, use GetRPYTemplated instead.
+func GetRPYContent(filename string, resolve func(name string) (content []byte, ok bool)) ([]byte, error) {
+	if resolve != nil {
+		if c, ok := resolve(filename); ok {
+			return c, nil
+		}
+	}
 	return rpyFiles.ReadFile("rpy/" + filename)
 }
 
@@ -34,12 +40,24 @@ type QuickSaveConfig struct {
 	QuickLoadKey string
 }
 
-// GetRPYTemplated returns the content of a templated RPY file
-// after applying the provided data.
-func GetRPYTemplated(filename string, data interface{}) ([]byte, error) {
-	content, err := rpyFiles.ReadFile("rpy/" + filename)
-	if err != nil {
-		return nil, err
+// GetRPYTemplated returns the content of a templated RPY file after
+// applying the provided data. If resolve is non-nil, it is tried first;
+// returning ok==false falls back to the embedded copy. This lets a caller
+// substitute a user-supplied .rpy/.tmpl file (e.g. from a [templates]
+// config override) without recompiling.
+func GetRPYTemplated(filename string, data interface{}, resolve func(name string) (content []byte, ok bool)) ([]byte, error) {
+	var content []byte
+	if resolve != nil {
+		if c, ok := resolve(filename); ok {
+			content = c
+		}
+	}
+	if content == nil {
+		var err error
+		content, err = rpyFiles.ReadFile("rpy/" + filename)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	tmpl, err := template.New(filename).Parse(string(content))
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/unren/unren-go/runner"
+)
+
+// jsonReportEntries accumulates RunEntry values from every Runner used
+// during this invocation - including concurrent batch-mode and --format
+// json/ndjson workers - for --json-report to serialize once the run ends.
+var (
+	jsonReportMu      sync.Mutex
+	jsonReportEntries []runner.RunEntry
+)
+
+// collectReport appends r's recorded run entries to the combined report.
+func collectReport(r *runner.Runner) {
+	entries := r.Report().Entries
+	if len(entries) == 0 {
+		return
+	}
+	jsonReportMu.Lock()
+	jsonReportEntries = append(jsonReportEntries, entries...)
+	jsonReportMu.Unlock()
+}
+
+// writeJSONReport serializes every entry collected so far to path.
+func writeJSONReport(path string) error {
+	jsonReportMu.Lock()
+	report := runner.RunReport{Entries: jsonReportEntries}
+	jsonReportMu.Unlock()
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
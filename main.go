@@ -4,12 +4,15 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 
+	"github.com/unren/unren-go/config"
 	"github.com/unren/unren-go/detector"
 	"github.com/unren/unren-go/patcher"
 	"github.com/unren/unren-go/runner"
@@ -18,7 +21,49 @@ import (
 
 const version = "0.0.5"
 
+// activeConfig is loaded once in main() against the best guess at the game
+// directory available before detection runs, and used for the action-flag
+// defaults decided there. Per-game hotkeys/template overrides are resolved
+// freshly per game via gameConfig, since batch mode processes many
+// directories that can each match a different [[game]] override.
+var activeConfig = config.Default()
+
+// runCtx is cancelled when the user presses Ctrl-C (see main), so the
+// extract/decompile calls driving it can kill a hung interpreter's whole
+// process group and abort the run cleanly instead of hanging forever.
+var runCtx = context.Background()
+
+// gameConfig resolves the merged config.Config for game's directory,
+// falling back to defaults (with a warning) if the files can't be parsed.
+func gameConfig(game *detector.GameInfo) *config.Config {
+	cfg, err := config.Load(game.RootDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  ! config: %v\n", err)
+		return config.Default()
+	}
+	return cfg
+}
+
+// patcherConfig builds a *patcher.Config for game from its resolved config.Config.
+func patcherConfig(game *detector.GameInfo) *patcher.Config {
+	cfg := gameConfig(game)
+	return &patcher.Config{
+		QuickSaveKey: cfg.QuickSaveKey,
+		QuickLoadKey: cfg.QuickLoadKey,
+		Templates:    cfg.Templates,
+	}
+}
+
 func main() {
+	if isSubcommand(os.Args[1:]) {
+		runSubcommand(os.Args[1:])
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	runCtx = ctx
+
 	// Parse command-line flags
 	var (
 		showVersion bool
@@ -33,6 +78,19 @@ func main() {
 		rollback  bool
 		all       bool
 		clean     bool
+
+		parallel          int
+		shard             string
+		verbose           bool
+		keepTemp          bool
+		tempBase          string
+		allowSystemPython bool
+
+		jobs            int
+		continueOnError bool
+
+		format     string
+		jsonReport string
 	)
 
 	// Custom Usage
@@ -57,10 +115,31 @@ func main() {
 
 		fmt.Fprintln(os.Stderr, "\nAdvanced:")
 		fmt.Fprintln(os.Stderr, "  --clean          Remove source files (.rpa/.rpyc) after SUCCESSFUL extraction/decompilation")
+		fmt.Fprintln(os.Stderr, "  -n, --parallel N Number of worker goroutines for extract/decompile (default: NumCPU)")
+		fmt.Fprintln(os.Stderr, "  --shard i/N      Only process the i-th of N shards of the file list (for splitting across machines)")
+		fmt.Fprintln(os.Stderr, "  --verbose        Disable parallel workers so output stays in strict order")
+		fmt.Fprintln(os.Stderr, "  -k, --keep-temp  Keep extracted helper scripts after the run instead of deleting them")
+		fmt.Fprintln(os.Stderr, "  --temp-dir DIR   Write helper scripts to DIR instead of the game root")
+		fmt.Fprintln(os.Stderr, "  --allow-system-python  Fall back to a system python3/python2 on PATH if the game has no usable bundled interpreter")
+
+		fmt.Fprintln(os.Stderr, "\nBatch Mode (pass multiple directories/globs instead of one):")
+		fmt.Fprintln(os.Stderr, "  --jobs N             Number of games to process concurrently (default: 1)")
+		fmt.Fprintln(os.Stderr, "  --continue-on-error  Keep processing remaining games after one fails")
+
+		fmt.Fprintln(os.Stderr, "\nOutput:")
+		fmt.Fprintln(os.Stderr, "  --format {text,json,ndjson}  Reporting format for single-game automation runs (default: text)")
+		fmt.Fprintln(os.Stderr, "  --json-report <path>         Write a per-file run report (tool, duration, stderr tail) to path")
+
+		fmt.Fprintln(os.Stderr, "\nPatch Management (subcommands, in place of [options]):")
+		fmt.Fprintln(os.Stderr, "  status    [game_directory]  Show install state of every tracked patch file")
+		fmt.Fprintln(os.Stderr, "  verify    [game_directory]  Exit non-zero if any tracked patch file was hand-edited")
+		fmt.Fprintln(os.Stderr, "  uninstall [game_directory]  Remove all tracked patch files (--force to remove edited ones)")
+		fmt.Fprintln(os.Stderr, "  upgrade   [game_directory]  Re-apply tracked patch files using a newer template version")
 
 		fmt.Fprintln(os.Stderr, "\nExamples:")
 		fmt.Fprintf(os.Stderr, "  %s -e -d /path/to/game\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "  %s --all .\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s --all --jobs 4 \"~/Games/*/game\" \"/mnt/vn/*\"\n", filepath.Base(os.Args[0]))
 	}
 
 	// Define flags (support both short and long versions where applicable)
@@ -88,6 +167,21 @@ func main() {
 	flag.BoolVar(&clean, "clean", false, "Remove source files on success")
 	flag.BoolVar(&clean, "c", false, "Remove source files on success (short)")
 
+	flag.IntVar(&parallel, "parallel", 0, "Worker goroutines for extract/decompile (default: NumCPU)")
+	flag.IntVar(&parallel, "n", 0, "Worker goroutines for extract/decompile (short)")
+	flag.StringVar(&shard, "shard", "", "Only process shard i/N of the file list, e.g. 0/4")
+	flag.BoolVar(&verbose, "verbose", false, "Disable parallel workers so output stays in strict order")
+	flag.BoolVar(&keepTemp, "keep-temp", false, "Keep extracted helper scripts after the run instead of deleting them")
+	flag.BoolVar(&keepTemp, "k", false, "Keep extracted helper scripts after the run (short)")
+	flag.StringVar(&tempBase, "temp-dir", "", "Write helper scripts to this directory instead of the game root")
+	flag.BoolVar(&allowSystemPython, "allow-system-python", false, "Fall back to a system python3/python2 on PATH if the game has no usable bundled interpreter")
+
+	flag.IntVar(&jobs, "jobs", 1, "Number of games to process concurrently in batch mode")
+	flag.BoolVar(&continueOnError, "continue-on-error", false, "Keep processing remaining games in batch mode after one fails")
+
+	flag.StringVar(&format, "format", "text", "Output format: text, json, or ndjson")
+	flag.StringVar(&jsonReport, "json-report", "", "Write a JSON run report (tool/duration/stderr per file) to this path")
+
 	flag.Parse()
 
 	if showVersion {
@@ -95,6 +189,49 @@ func main() {
 		return
 	}
 
+	outFormat, err := parseOutputFormat(format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  ! %v\n", err)
+		os.Exit(1)
+	}
+
+	if jsonReport != "" {
+		defer func() {
+			if err := writeJSONReport(jsonReport); err != nil {
+				fmt.Fprintf(os.Stderr, "  ! json-report: %v\n", err)
+			}
+		}()
+	}
+
+	setFlags := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	peekDir := "."
+	if flag.NArg() > 0 && !isBatchMode(flag.Args()) {
+		peekDir = strings.Trim(flag.Arg(0), "\"'")
+	}
+	if cfg, err := config.Load(peekDir); err != nil {
+		fmt.Fprintf(os.Stderr, "  ! config: %v\n", err)
+	} else {
+		activeConfig = cfg
+	}
+	applyConfigActionDefaults(activeConfig, setFlags, &extract, &decompile, &console, &quicksave, &skip, &rollback, &clean)
+
+	// Determine if running in automation mode
+	automationMode := extract || decompile || console || quicksave || skip || rollback || all
+
+	// Multiple positional args, or a single pattern containing glob
+	// metacharacters, switches us into batch mode: expand every pattern to
+	// its matching game directories and process them all.
+	if isBatchMode(flag.Args()) {
+		if !automationMode {
+			fmt.Println("  Batch mode requires at least one action flag (-e, -d, --console, --quicksave, --skip, --rollback, --all).")
+			return
+		}
+		runBatchMode(flag.Args(), jobs, continueOnError, extract, decompile, console, quicksave, skip, rollback, all, clean, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython)
+		return
+	}
+
 	// Handle positional argument for game directory
 	if flag.NArg() > 0 {
 		gameDir = strings.Trim(flag.Arg(0), "\"'")
@@ -102,9 +239,6 @@ func main() {
 		gameDir = "."
 	}
 
-	// Determine if running in automation mode
-	automationMode := extract || decompile || console || quicksave || skip || rollback || all
-
 	// Detect game loop
 	var game *detector.GameInfo
 
@@ -166,20 +300,25 @@ func main() {
 		}
 	}
 
+	if automationMode && outFormat != FormatText {
+		runReportedActions(game, extract, decompile, console, quicksave, skip, rollback, all, clean, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython, outFormat)
+		return
+	}
+
 	printGameInfo(game)
 
 	if automationMode {
 		if all {
-			handleAllOptions(game, clean)
+			handleAllOptions(game, clean, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython)
 			return
 		}
 
 		if extract {
-			handleExtractRPA(game, clean)
+			handleExtractRPA(game, clean, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython)
 			fmt.Println()
 		}
 		if decompile {
-			handleDecompileRPYC(game, clean)
+			handleDecompileRPYC(game, clean, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython)
 			fmt.Println()
 		}
 		if console {
@@ -211,9 +350,9 @@ func main() {
 
 		switch option {
 		case "1":
-			handleExtractRPA(game, false) // Cleaning disabled in interactive mode for safety unless we add an option
+			handleExtractRPA(game, false, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython) // Cleaning disabled in interactive mode for safety unless we add an option
 		case "2":
-			handleDecompileRPYC(game, false)
+			handleDecompileRPYC(game, false, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython)
 		case "3":
 			handleEnableConsole(game)
 		case "4":
@@ -225,7 +364,7 @@ func main() {
 		case "7":
 			handleOptionsGroup1(game)
 		case "8":
-			handleAllOptions(game, false)
+			handleAllOptions(game, false, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython)
 		default:
 			return
 		}
@@ -294,33 +433,61 @@ func printMenu() {
 	fmt.Println()
 }
 
-func handleExtractRPA(game *detector.GameInfo, clean bool) {
+func handleExtractRPA(game *detector.GameInfo, clean bool, parallel int, shard string, verbose bool, keepTemp bool, tempBase string, allowSystemPython bool) {
 	if !game.HasRPAFiles() {
 		fmt.Println("  There were no .rpa files to unpack.")
 		return
 	}
 
+	keep, err := shardFilter(shard)
+	if err != nil {
+		fmt.Printf("    ! %v\n", err)
+		return
+	}
+	targets := filterPaths(game.RPAFiles, keep)
+	if len(targets) == 0 {
+		fmt.Println("  No RPA files in this shard.")
+		return
+	}
+
 	// Use Python-based extraction via runner (matches original batch script behavior)
-	r, err := runner.NewRunner(game)
+	r, err := runner.NewRunner(game, activeConfig.PythonPath)
 	if err != nil {
 		fmt.Printf("    ! Failed to find Python interpreter: %v\n", err)
 		fmt.Println("      RPA extraction requires the game's bundled Python interpreter.")
 		return
 	}
+	r.KeepTemp = keepTemp
+	r.TempBase = tempBase
+	r.AllowSystemPython = allowSystemPython
+	if err := r.SetupTempDir(); err != nil {
+		fmt.Printf("    ! Failed to set up extraction scripts: %v\n", err)
+		return
+	}
 	defer r.Cleanup()
+	defer collectReport(r)
 
 	fmt.Println("  Extracting RPA archives...")
 	fmt.Println()
 
-	totalErrors := 0
-
-	for _, rpaPath := range game.RPAFiles {
+	results := runParallel(r, targets, parallelism(parallel, verbose), func(w *runner.Runner, rpaPath string, log func(string, ...interface{})) error {
 		relPath, _ := filepath.Rel(game.GameDir, rpaPath)
 		info, _ := os.Stat(rpaPath)
-		fmt.Printf("    + Unpacking \"%s\" - %s\n", relPath, utils.FormatBytes(info.Size()))
+		log("    + Unpacking \"%s\" - %s", relPath, utils.FormatBytes(info.Size()))
+
+		if err := w.ExtractRPAContext(runCtx, rpaPath); err != nil {
+			log("    ! Failed to extract %s: %v", relPath, err)
+			return err
+		}
+		return nil
+	})
 
-		if err := r.ExtractRPA(rpaPath); err != nil {
-			fmt.Printf("    ! Failed to extract %s: %v\n", relPath, err)
+	totalErrors := 0
+	for _, res := range results {
+		for _, line := range res.lines {
+			fmt.Println(line)
+		}
+		if res.err != nil {
 			totalErrors++
 		}
 	}
@@ -335,7 +502,7 @@ func handleExtractRPA(game *detector.GameInfo, clean bool) {
 			fmt.Println()
 			fmt.Println("  Cleaning up RPA files...")
 			cleaned := 0
-			for _, rpaPath := range game.RPAFiles {
+			for _, rpaPath := range targets {
 				if err := os.Remove(rpaPath); err == nil {
 					cleaned++
 				} else {
@@ -353,22 +520,53 @@ func handleExtractRPA(game *detector.GameInfo, clean bool) {
 	}
 }
 
-func handleDecompileRPYC(game *detector.GameInfo, clean bool) {
+// filterPaths returns the subset of paths for which keep returns true,
+// preserving order.
+func filterPaths(paths []string, keep func(string) bool) []string {
+	out := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if keep(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func handleDecompileRPYC(game *detector.GameInfo, clean bool, parallel int, shard string, verbose bool, keepTemp bool, tempBase string, allowSystemPython bool) {
 	if !game.HasRPYCFiles() {
 		fmt.Println("  There were no .rpyc files to decompile.")
 		return
 	}
 
+	keep, err := shardFilter(shard)
+	if err != nil {
+		fmt.Printf("    ! %v\n", err)
+		return
+	}
+	targets := filterPaths(game.RPYCFiles, keep)
+	if len(targets) == 0 {
+		fmt.Println("  No RPYC files in this shard.")
+		return
+	}
+
 	fmt.Println("  Setting up decompiler...")
 
 	// Create runner to use embedded Python scripts
-	r, err := runner.NewRunner(game)
+	r, err := runner.NewRunner(game, activeConfig.PythonPath)
 	if err != nil {
 		fmt.Printf("    ! Failed to find Python interpreter: %v\n", err)
 		fmt.Println("    ! RPYC decompilation requires the game's bundled Python interpreter.")
 		return
 	}
+	r.KeepTemp = keepTemp
+	r.TempBase = tempBase
+	r.AllowSystemPython = allowSystemPython
+	if err := r.SetupUnrpyc(); err != nil {
+		fmt.Printf("    ! Failed to set up decompiler: %v\n", err)
+		return
+	}
 	defer r.Cleanup()
+	defer collectReport(r)
 
 	pyVersion := "Python 2"
 	if r.IsPython3 {
@@ -380,10 +578,7 @@ func handleDecompileRPYC(game *detector.GameInfo, clean bool) {
 	fmt.Println("  Searching for rpyc files...")
 	fmt.Println()
 
-	success, skipped, failed, err := r.DecompileAllRPYC()
-	if err != nil {
-		fmt.Printf("    ! Decompilation error: %v\n", err)
-	}
+	success, skipped, failed := decompileBatch(r, game, targets, parallelism(parallel, verbose))
 
 	fmt.Println()
 	// Match batch script summary format
@@ -414,7 +609,7 @@ func handleDecompileRPYC(game *detector.GameInfo, clean bool) {
 		fmt.Println()
 		fmt.Println("  Cleaning up RPYC files...")
 		cleaned := 0
-		for _, rpycPath := range game.RPYCFiles {
+		for _, rpycPath := range targets {
 			if err := os.Remove(rpycPath); err == nil {
 				cleaned++
 			} else {
@@ -428,10 +623,65 @@ func handleDecompileRPYC(game *detector.GameInfo, clean bool) {
 	}
 }
 
+// errIgnoredRPYC and errSkippedRPYC are sentinels returned by the
+// decompileBatch job func so its caller can tell "not counted" (un.rpyc)
+// apart from "already decompiled" without adding another return value.
+var (
+	errIgnoredRPYC = fmt.Errorf("ignored")
+	errSkippedRPYC = fmt.Errorf("already decompiled")
+)
+
+// decompileBatch decompiles targets across parallel workers, preserving the
+// original per-file log output, and returns success/skipped/failed counts.
+func decompileBatch(r *runner.Runner, game *detector.GameInfo, targets []string, workers int) (success, skipped, failed int) {
+	results := runParallel(r, targets, workers, func(w *runner.Runner, rpycPath string, log func(string, ...interface{})) error {
+		baseName := filepath.Base(rpycPath)
+		nameWithoutExt := strings.TrimSuffix(baseName, filepath.Ext(baseName))
+		if strings.ToLower(nameWithoutExt) == "un" {
+			return errIgnoredRPYC
+		}
+
+		rpyPath := strings.TrimSuffix(rpycPath, filepath.Ext(rpycPath)) + ".rpy"
+		if _, err := os.Stat(rpyPath); err == nil {
+			log("    \"%s.rpy\" already exists - skipped", nameWithoutExt)
+			return errSkippedRPYC
+		}
+
+		info, _ := os.Stat(rpycPath)
+		log("    + Decompiling \"%s\" - %d bytes", baseName, info.Size())
+
+		if err := w.DecompileRPYCContext(runCtx, rpycPath); err != nil {
+			log("    - Failed to decompile \"%s\". Error: %v", baseName, err)
+			return err
+		}
+		if _, err := os.Stat(rpyPath); err != nil {
+			log("    - Failed to create RPY file: %s.rpy not found.", nameWithoutExt)
+			return fmt.Errorf("%s.rpy not written", nameWithoutExt)
+		}
+		return nil
+	})
+
+	for _, res := range results {
+		for _, line := range res.lines {
+			fmt.Println(line)
+		}
+		switch res.err {
+		case errIgnoredRPYC:
+		case errSkippedRPYC:
+			skipped++
+		case nil:
+			success++
+		default:
+			failed++
+		}
+	}
+	return success, skipped, failed
+}
+
 func handleEnableConsole(game *detector.GameInfo) {
 	fmt.Println("  Creating Developer/Console file...")
 
-	p := patcher.New(game.GameDir, nil)
+	p := patcher.New(game.GameDir, patcherConfig(game))
 	if err := p.EnableConsole(); err != nil {
 		fmt.Printf("    ! Failed: %v\n", err)
 		return
@@ -444,7 +694,7 @@ func handleEnableConsole(game *detector.GameInfo) {
 func handleEnableQuickSave(game *detector.GameInfo) {
 	fmt.Println("  Creating Quick Save/Quick Load file...")
 
-	p := patcher.New(game.GameDir, nil)
+	p := patcher.New(game.GameDir, patcherConfig(game))
 	if err := p.EnableQuickSave(); err != nil {
 		fmt.Printf("    ! Failed: %v\n", err)
 		return
@@ -458,7 +708,7 @@ func handleEnableQuickSave(game *detector.GameInfo) {
 func handleEnableSkip(game *detector.GameInfo) {
 	fmt.Println("  Creating skip file...")
 
-	p := patcher.New(game.GameDir, nil)
+	p := patcher.New(game.GameDir, patcherConfig(game))
 	if err := p.EnableSkip(); err != nil {
 		fmt.Printf("    ! Failed: %v\n", err)
 		return
@@ -470,7 +720,7 @@ func handleEnableSkip(game *detector.GameInfo) {
 func handleEnableRollback(game *detector.GameInfo) {
 	fmt.Println("  Creating rollback file...")
 
-	p := patcher.New(game.GameDir, nil)
+	p := patcher.New(game.GameDir, patcherConfig(game))
 	if err := p.EnableRollback(); err != nil {
 		fmt.Printf("    ! Failed: %v\n", err)
 		return
@@ -489,10 +739,10 @@ func handleOptionsGroup1(game *detector.GameInfo) {
 	handleEnableRollback(game)
 }
 
-func handleAllOptions(game *detector.GameInfo, clean bool) {
-	handleExtractRPA(game, clean)
+func handleAllOptions(game *detector.GameInfo, clean bool, parallel int, shard string, verbose bool, keepTemp bool, tempBase string, allowSystemPython bool) {
+	handleExtractRPA(game, clean, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython)
 	fmt.Println()
-	handleDecompileRPYC(game, clean)
+	handleDecompileRPYC(game, clean, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython)
 	fmt.Println()
 	handleOptionsGroup1(game)
 }
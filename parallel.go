@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"runtime"
+	"sync"
+
+	"github.com/unren/unren-go/runner"
+)
+
+// parallelism resolves the effective worker count for a batch job.
+// Verbose runs are forced single-threaded so Python output stays readable
+// instead of interleaving across workers.
+func parallelism(requested int, verbose bool) int {
+	if verbose {
+		return 1
+	}
+	if requested > 0 {
+		return requested
+	}
+	return runtime.NumCPU()
+}
+
+// shardFilter parses a "-shard i/N" value into a predicate that keeps only
+// the paths belonging to shard i, hashed with fnv-1a so the split is stable
+// across machines running the same file list.
+func shardFilter(shard string) (func(path string) bool, error) {
+	if shard == "" {
+		return func(string) bool { return true }, nil
+	}
+
+	var idx, total int
+	if _, err := fmt.Sscanf(shard, "%d/%d", &idx, &total); err != nil || total <= 0 || idx < 0 || idx >= total {
+		return nil, fmt.Errorf("invalid -shard value %q, expected i/N", shard)
+	}
+
+	return func(path string) bool {
+		h := fnv.New32a()
+		h.Write([]byte(path))
+		return int(h.Sum32()%uint32(total)) == idx
+	}, nil
+}
+
+// fileResult carries the buffered log lines and outcome for one path
+// processed by runParallel, so callers can flush output in input order
+// regardless of which worker finished it.
+type fileResult struct {
+	path  string
+	lines []string
+	err   error
+}
+
+// runParallel fans paths out to n workers, each driving its own
+// *runner.Runner cloned from base (Setup has already run once on base, so
+// cloning just shares the resolved Python/TempDir info instead of racing
+// the same setup logic). fn receives a log func that buffers lines for the
+// job; runParallel returns results indexed identically to paths so the
+// caller can flush them back in order.
+func runParallel(base *runner.Runner, paths []string, n int, fn func(w *runner.Runner, path string, log func(format string, a ...interface{})) error) []fileResult {
+	if n < 1 {
+		n = 1
+	}
+	if n > len(paths) {
+		n = len(paths)
+	}
+	if n < 1 {
+		return nil
+	}
+
+	results := make([]fileResult, len(paths))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker := base.Clone()
+			for i := range jobs {
+				var lines []string
+				log := func(format string, a ...interface{}) {
+					lines = append(lines, fmt.Sprintf(format, a...))
+				}
+				err := fn(worker, paths[i], log)
+				results[i] = fileResult{path: paths[i], lines: lines, err: err}
+			}
+		}()
+	}
+
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
@@ -3,19 +3,38 @@
 package patcher
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/unren/unren-go/files"
 )
 
+// manifestFileName is the record, written into gameDir on every apply, of
+// every file the patcher has generated there.
+const manifestFileName = "unren-manifest.json"
+
+// templateVersion is bumped whenever an embedded .rpy template's content
+// changes in a way `unren upgrade` should pick up. It's the same version
+// recorded against every entry written by this build.
+const templateVersion = 1
+
 // Config holds configuration for the patcher.
 type Config struct {
 	// QuickSaveKey is the Ren'Py key constant for quick save (default: K_F5)
 	QuickSaveKey string
 	// QuickLoadKey is the Ren'Py key constant for quick load (default: K_F9)
 	QuickLoadKey string
+	// Templates maps an entry in files.RPYFiles to an on-disk file that
+	// should be used instead of the embedded copy, e.g. from a config
+	// [templates] override. Nil/missing entries fall back to embedded.
+	Templates map[string]string
 }
 
 // DefaultConfig returns the default patcher configuration.
@@ -43,14 +62,29 @@ func New(gameDir string, config *Config) *Patcher {
 	}
 }
 
+// resolveTemplate looks up name in the patcher's configured template
+// overrides, reading the on-disk replacement if one is set. It's passed to
+// files.GetRPYContent/GetRPYTemplated as their fallback resolver.
+func (p *Patcher) resolveTemplate(name string) ([]byte, bool) {
+	path, ok := p.config.Templates[name]
+	if !ok {
+		return nil, false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return content, true
+}
+
 // EnableConsole creates unren-dev.rpy to enable developer console and menu.
 // Console: SHIFT+O | Dev Menu: SHIFT+D
 func (p *Patcher) EnableConsole() error {
-	content, err := files.GetRPYContent(files.RPYFiles.Dev)
+	content, err := files.GetRPYContent(files.RPYFiles.Dev, p.resolveTemplate)
 	if err != nil {
 		return fmt.Errorf("failed to load dev template: %w", err)
 	}
-	return p.writeFile("unren-dev.rpy", content)
+	return p.writeFile("unren-dev.rpy", content, files.RPYFiles.Dev, nil)
 }
 
 // EnableQuickSave creates unren-quick.rpy to enable quick save/load hotkeys.
@@ -60,30 +94,34 @@ func (p *Patcher) EnableQuickSave() error {
 		QuickLoadKey: p.config.QuickLoadKey,
 	}
 
-	content, err := files.GetRPYTemplated(files.RPYFiles.Quick, data)
+	content, err := files.GetRPYTemplated(files.RPYFiles.Quick, data, p.resolveTemplate)
 	if err != nil {
 		return fmt.Errorf("failed to process quick save template: %w", err)
 	}
 
-	return p.writeFile("unren-quick.rpy", content)
+	cfg := map[string]string{
+		"QuickSaveKey": p.config.QuickSaveKey,
+		"QuickLoadKey": p.config.QuickLoadKey,
+	}
+	return p.writeFile("unren-quick.rpy", content, files.RPYFiles.Quick, cfg)
 }
 
 // EnableSkip creates unren-skip.rpy to enable skipping unseen content.
 func (p *Patcher) EnableSkip() error {
-	content, err := files.GetRPYContent(files.RPYFiles.Skip)
+	content, err := files.GetRPYContent(files.RPYFiles.Skip, p.resolveTemplate)
 	if err != nil {
 		return fmt.Errorf("failed to load skip template: %w", err)
 	}
-	return p.writeFile("unren-skip.rpy", content)
+	return p.writeFile("unren-skip.rpy", content, files.RPYFiles.Skip, nil)
 }
 
 // EnableRollback creates unren-rollback.rpy to enable infinite rollback.
 func (p *Patcher) EnableRollback() error {
-	content, err := files.GetRPYContent(files.RPYFiles.Rollback)
+	content, err := files.GetRPYContent(files.RPYFiles.Rollback, p.resolveTemplate)
 	if err != nil {
 		return fmt.Errorf("failed to load rollback template: %w", err)
 	}
-	return p.writeFile("unren-rollback.rpy", content)
+	return p.writeFile("unren-rollback.rpy", content, files.RPYFiles.Rollback, nil)
 }
 
 // EnableAll enables all patching features (console, quick save, skip, rollback).
@@ -103,23 +141,47 @@ func (p *Patcher) EnableAll() error {
 	return nil
 }
 
-// PatchFiles returns the list of all patch file names.
-func PatchFiles() []string {
-	return []string{
-		"unren-dev.rpy",
-		"unren-quick.rpy",
-		"unren-skip.rpy",
-		"unren-rollback.rpy",
+// RemoveAll removes every patch file recorded in the manifest, skipping (and
+// returning an error for) any file that was modified after we installed it,
+// unless force is set. This keeps a hand-edited unren-*.rpy safe from being
+// silently discarded, and never touches a third-party .rpy that merely
+// happens to share the unren- prefix but was never recorded.
+func (p *Patcher) RemoveAll(force bool) error {
+	manifest, err := loadManifest(p.gameDir)
+	if err != nil {
+		return err
 	}
-}
 
-// RemoveAll removes all unren patch files from the game directory.
-func (p *Patcher) RemoveAll() error {
-	for _, f := range PatchFiles() {
-		path := filepath.Join(p.gameDir, f)
+	var modified []string
+	for relPath, entry := range manifest.Entries {
+		path := filepath.Join(p.gameDir, relPath)
+
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			delete(manifest.Entries, relPath)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", relPath, err)
+		}
+
+		if !force && sha256Hex(data) != entry.SHA256 {
+			modified = append(modified, relPath)
+			continue
+		}
+
 		if err := p.removeIfExists(path); err != nil {
 			return err
 		}
+		delete(manifest.Entries, relPath)
+	}
+
+	if err := manifest.save(p.gameDir); err != nil {
+		return err
+	}
+	if len(modified) > 0 {
+		sort.Strings(modified)
+		return fmt.Errorf("%s modified after unren installed it; use --force to remove anyway", strings.Join(modified, ", "))
 	}
 	return nil
 }
@@ -132,11 +194,19 @@ func (p *Patcher) removeIfExists(path string) error {
 	return nil
 }
 
-// writeFile writes content to a file in the game directory.
-// It removes any existing file first to ensure clean state.
-func (p *Patcher) writeFile(filename string, content []byte) error {
+// writeFile writes content to a file in the game directory and records the
+// write in unren-manifest.json so later runs (Status/Verify/RemoveAll/
+// Upgrade) know what they're looking at. template and config identify which
+// embedded template produced the content and the values it was rendered
+// with, so Upgrade can re-render it later with the same choices.
+func (p *Patcher) writeFile(filename string, content []byte, template string, config map[string]string) error {
 	path := filepath.Join(p.gameDir, filename)
 
+	manifest, err := loadManifest(p.gameDir)
+	if err != nil {
+		return err
+	}
+
 	// Remove existing file if present
 	_ = p.removeIfExists(path)
 
@@ -144,5 +214,186 @@ func (p *Patcher) writeFile(filename string, content []byte) error {
 		return fmt.Errorf("failed to write %s: %w", filename, err)
 	}
 
+	manifest.Entries[filename] = ManifestEntry{
+		RelPath:         filename,
+		SHA256:          sha256Hex(content),
+		Template:        template,
+		TemplateVersion: templateVersion,
+		Config:          config,
+		Timestamp:       time.Now(),
+	}
+
+	return manifest.save(p.gameDir)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ManifestEntry records one file the patcher has written to a game
+// directory, so later runs can tell whether it's still installed, still
+// matches what we wrote, or needs upgrading.
+type ManifestEntry struct {
+	RelPath         string            `json:"rel_path"`
+	SHA256          string            `json:"sha256"`
+	Template        string            `json:"template"`
+	TemplateVersion int               `json:"template_version"`
+	Config          map[string]string `json:"config,omitempty"`
+	Timestamp       time.Time         `json:"timestamp"`
+}
+
+// Manifest is the on-disk unren-manifest.json record of every file the
+// patcher has written to a game directory, keyed by RelPath.
+type Manifest struct {
+	Entries map[string]ManifestEntry `json:"entries"`
+}
+
+// loadManifest reads unren-manifest.json from gameDir, returning an empty
+// manifest (not an error) if it doesn't exist yet.
+func loadManifest(gameDir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(gameDir, manifestFileName))
+	if os.IsNotExist(err) {
+		return &Manifest{Entries: map[string]ManifestEntry{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if m.Entries == nil {
+		m.Entries = map[string]ManifestEntry{}
+	}
+	return &m, nil
+}
+
+func (m *Manifest) save(gameDir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(gameDir, manifestFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
 	return nil
 }
+
+// PatchState describes how an installed patch file compares to what the
+// manifest recorded for it.
+type PatchState string
+
+const (
+	StateInstalled      PatchState = "installed"
+	StateMissing        PatchState = "missing"
+	StateModifiedByUser PatchState = "modified"
+	StateOutdated       PatchState = "outdated"
+)
+
+// PatchStatus reports the on-disk state of one manifest entry.
+type PatchStatus struct {
+	RelPath string
+	State   PatchState
+	Entry   ManifestEntry
+}
+
+// Status reports the install state of every file the patcher has ever
+// written to this game directory, per the manifest.
+func (p *Patcher) Status() ([]PatchStatus, error) {
+	manifest, err := loadManifest(p.gameDir)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]PatchStatus, 0, len(manifest.Entries))
+	for relPath, entry := range manifest.Entries {
+		data, err := os.ReadFile(filepath.Join(p.gameDir, relPath))
+		state := StateInstalled
+		switch {
+		case os.IsNotExist(err):
+			state = StateMissing
+		case err != nil:
+			return nil, fmt.Errorf("failed to read %s: %w", relPath, err)
+		case sha256Hex(data) != entry.SHA256:
+			state = StateModifiedByUser
+		case entry.TemplateVersion < templateVersion:
+			state = StateOutdated
+		}
+		statuses = append(statuses, PatchStatus{RelPath: relPath, State: state, Entry: entry})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].RelPath < statuses[j].RelPath })
+	return statuses, nil
+}
+
+// Verify returns an error listing every manifest-tracked file whose content
+// no longer matches its recorded hash, i.e. the user edited it by hand.
+func (p *Patcher) Verify() error {
+	statuses, err := p.Status()
+	if err != nil {
+		return err
+	}
+
+	var modified []string
+	for _, s := range statuses {
+		if s.State == StateModifiedByUser {
+			modified = append(modified, s.RelPath)
+		}
+	}
+	if len(modified) > 0 {
+		return fmt.Errorf("user-modified patch file(s): %s", strings.Join(modified, ", "))
+	}
+	return nil
+}
+
+// Upgrade re-applies every manifest entry whose recorded template version is
+// older than the version embedded in this build, reusing the config values
+// it was originally installed with. It returns the relative paths upgraded.
+func (p *Patcher) Upgrade() ([]string, error) {
+	statuses, err := p.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var upgraded []string
+	for _, s := range statuses {
+		if s.State != StateOutdated {
+			continue
+		}
+		if err := p.reapply(s.Entry); err != nil {
+			return upgraded, fmt.Errorf("failed to upgrade %s: %w", s.RelPath, err)
+		}
+		upgraded = append(upgraded, s.RelPath)
+	}
+	return upgraded, nil
+}
+
+// reapply re-runs whichever Enable* method produced entry, temporarily
+// substituting the config values it was originally recorded with.
+func (p *Patcher) reapply(entry ManifestEntry) error {
+	switch entry.Template {
+	case files.RPYFiles.Dev:
+		return p.EnableConsole()
+	case files.RPYFiles.Quick:
+		original := p.config
+		cfg := *original
+		if v, ok := entry.Config["QuickSaveKey"]; ok {
+			cfg.QuickSaveKey = v
+		}
+		if v, ok := entry.Config["QuickLoadKey"]; ok {
+			cfg.QuickLoadKey = v
+		}
+		p.config = &cfg
+		err := p.EnableQuickSave()
+		p.config = original
+		return err
+	case files.RPYFiles.Skip:
+		return p.EnableSkip()
+	case files.RPYFiles.Rollback:
+		return p.EnableRollback()
+	default:
+		return fmt.Errorf("unknown template %q", entry.Template)
+	}
+}
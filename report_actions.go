@@ -0,0 +1,295 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unren/unren-go/detector"
+	"github.com/unren/unren-go/patcher"
+	"github.com/unren/unren-go/runner"
+	"github.com/unren/unren-go/utils"
+)
+
+// runReportedActions is the --format json/ndjson counterpart to the plain
+// handle* functions: it performs the same work but routes all progress
+// through a Reporter instead of fmt.Println, so stdout carries only
+// machine-readable output.
+func runReportedActions(game *detector.GameInfo, extract, decompile, console, quicksave, skip, rollback, all, clean bool, parallel int, shard string, verbose bool, keepTemp bool, tempBase string, allowSystemPython bool, format OutputFormat) {
+	var rep Reporter = TextReporter{}
+	if format == FormatNDJSON {
+		rep = NewNDJSONReporter()
+	}
+
+	summary := runActionsReported(game, extract || all, decompile || all, console || all, quicksave || all, skip || all, rollback || all, clean, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython, rep)
+
+	if format == FormatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(summary)
+	}
+}
+
+// runActionsReported performs the requested actions against game and
+// returns a RunSummary, emitting a Reporter event for every file and patch
+// processed along the way.
+func runActionsReported(game *detector.GameInfo, extract, decompile, console, quicksave, skip, rollback bool, clean bool, parallel int, shard string, verbose bool, keepTemp bool, tempBase string, allowSystemPython bool, rep Reporter) *RunSummary {
+	summary := &RunSummary{}
+	summary.Game.Name = game.Name
+	summary.Game.GameDir = game.GameDir
+	summary.Game.RenPyVersion = game.RenPyVersion
+
+	rep.Event("game.detected", map[string]interface{}{
+		"name":          game.Name,
+		"game_dir":      game.GameDir,
+		"renpy_version": game.RenPyVersion,
+		"rpa_count":     len(game.RPAFiles),
+		"rpyc_count":    len(game.RPYCFiles),
+	})
+
+	if extract && game.HasRPAFiles() {
+		summary.Actions = append(summary.Actions, "extract")
+		summary.Extract = reportExtract(game, clean, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython, rep)
+	}
+	if decompile && game.HasRPYCFiles() {
+		summary.Actions = append(summary.Actions, "decompile")
+		summary.Decompile = reportDecompile(game, clean, parallel, shard, verbose, keepTemp, tempBase, allowSystemPython, rep)
+		if game.RenPyVersion >= 8 {
+			summary.Game.Python = "python3"
+		} else {
+			summary.Game.Python = "python2"
+		}
+	}
+	if console {
+		summary.Actions = append(summary.Actions, "console")
+		summary.Patches = appendPatch(summary.Patches, game, rep, "unren-dev.rpy", (*patcher.Patcher).EnableConsole)
+	}
+	if quicksave {
+		summary.Actions = append(summary.Actions, "quicksave")
+		summary.Patches = appendPatch(summary.Patches, game, rep, "unren-quick.rpy", (*patcher.Patcher).EnableQuickSave)
+	}
+	if skip {
+		summary.Actions = append(summary.Actions, "skip")
+		summary.Patches = appendPatch(summary.Patches, game, rep, "unren-skip.rpy", (*patcher.Patcher).EnableSkip)
+	}
+	if rollback {
+		summary.Actions = append(summary.Actions, "rollback")
+		summary.Patches = appendPatch(summary.Patches, game, rep, "unren-rollback.rpy", (*patcher.Patcher).EnableRollback)
+	}
+
+	rep.Event("run.done", map[string]interface{}{"actions": summary.Actions})
+	return summary
+}
+
+// reportExtract mirrors handleExtractRPA but reports via rep instead of
+// printing, returning the per-file outcomes for the JSON summary.
+func reportExtract(game *detector.GameInfo, clean bool, parallel int, shard string, verbose bool, keepTemp bool, tempBase string, allowSystemPython bool, rep Reporter) []FileOutcome {
+	keep, err := shardFilter(shard)
+	if err != nil {
+		rep.Event("rpa.extract.error", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+	targets := filterPaths(game.RPAFiles, keep)
+
+	r, err := runner.NewRunner(game, activeConfig.PythonPath)
+	if err != nil {
+		rep.Event("rpa.extract.error", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+	r.KeepTemp = keepTemp
+	r.TempBase = tempBase
+	r.AllowSystemPython = allowSystemPython
+	r.SuppressChildStdout = true
+	if err := r.SetupTempDir(); err != nil {
+		rep.Event("rpa.extract.error", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+	defer r.Cleanup()
+	defer collectReport(r)
+
+	outcomes := runnerPoolMap(r, targets, parallelism(parallel, verbose), func(w *runner.Runner, rpaPath string) FileOutcome {
+		rep.Event("rpa.extract.start", map[string]interface{}{"path": rpaPath})
+		start := time.Now()
+		extractErr := w.ExtractRPAContext(runCtx, rpaPath)
+		dur := time.Since(start)
+
+		info, _ := os.Stat(rpaPath)
+		var size int64
+		if info != nil {
+			size = info.Size()
+		}
+
+		outcome := FileOutcome{Path: rpaPath, Size: size, Duration: dur.String(), Status: "ok"}
+		if extractErr != nil {
+			outcome.Status = "failed"
+			outcome.Error = extractErr.Error()
+		}
+		rep.Event("rpa.extract.done", map[string]interface{}{"path": rpaPath, "status": outcome.Status, "duration": outcome.Duration})
+		return outcome
+	})
+
+	if clean && !anyFailed(outcomes) {
+		for _, path := range targets {
+			os.Remove(path)
+		}
+	}
+
+	if found, err := utils.FindFilesWithExtension(game.GameDir, ".rpyc"); err == nil {
+		game.RPYCFiles = found
+	}
+
+	return outcomes
+}
+
+// reportDecompile mirrors handleDecompileRPYC/decompileBatch but reports
+// via rep instead of printing.
+func reportDecompile(game *detector.GameInfo, clean bool, parallel int, shard string, verbose bool, keepTemp bool, tempBase string, allowSystemPython bool, rep Reporter) []FileOutcome {
+	keep, err := shardFilter(shard)
+	if err != nil {
+		rep.Event("rpyc.decompile.error", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+	targets := filterPaths(game.RPYCFiles, keep)
+
+	r, err := runner.NewRunner(game, activeConfig.PythonPath)
+	if err != nil {
+		rep.Event("rpyc.decompile.error", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+	r.KeepTemp = keepTemp
+	r.TempBase = tempBase
+	r.AllowSystemPython = allowSystemPython
+	r.SuppressChildStdout = true
+	if err := r.SetupUnrpyc(); err != nil {
+		rep.Event("rpyc.decompile.error", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+	defer r.Cleanup()
+	defer collectReport(r)
+
+	outcomes := runnerPoolMap(r, targets, parallelism(parallel, verbose), func(w *runner.Runner, rpycPath string) FileOutcome {
+		baseName := filepath.Base(rpycPath)
+		nameWithoutExt := strings.TrimSuffix(baseName, filepath.Ext(baseName))
+		rpyPath := strings.TrimSuffix(rpycPath, filepath.Ext(rpycPath)) + ".rpy"
+
+		if strings.ToLower(nameWithoutExt) == "un" {
+			return FileOutcome{Path: rpycPath, Status: "ignored"}
+		}
+		if _, err := os.Stat(rpyPath); err == nil {
+			rep.Event("rpyc.decompile.done", map[string]interface{}{"path": rpycPath, "status": "skipped"})
+			return FileOutcome{Path: rpycPath, Status: "skipped"}
+		}
+
+		info, _ := os.Stat(rpycPath)
+		var size int64
+		if info != nil {
+			size = info.Size()
+		}
+
+		rep.Event("rpyc.decompile.start", map[string]interface{}{"path": rpycPath})
+		start := time.Now()
+		decompileErr := w.DecompileRPYCContext(runCtx, rpycPath)
+		dur := time.Since(start)
+
+		outcome := FileOutcome{Path: rpycPath, Size: size, Duration: dur.String(), Status: "ok"}
+		switch {
+		case decompileErr != nil:
+			outcome.Status = "failed"
+			outcome.Error = decompileErr.Error()
+		case !utils.FileExists(rpyPath):
+			outcome.Status = "failed"
+			outcome.Error = nameWithoutExt + ".rpy not written"
+		}
+		rep.Event("rpyc.decompile.done", map[string]interface{}{"path": rpycPath, "status": outcome.Status, "duration": outcome.Duration})
+		return outcome
+	})
+
+	if clean && !anyFailed(outcomes) {
+		for _, path := range targets {
+			os.Remove(path)
+		}
+	}
+
+	return outcomes
+}
+
+// anyFailed reports whether any outcome in results has status "failed".
+func anyFailed(results []FileOutcome) bool {
+	for _, r := range results {
+		if r.Status == "failed" {
+			return true
+		}
+	}
+	return false
+}
+
+// appendPatch applies a patcher.Patcher method, hashes the file it wrote,
+// reports the outcome, and appends it to patches.
+func appendPatch(patches []PatchOutcome, game *detector.GameInfo, rep Reporter, filename string, apply func(*patcher.Patcher) error) []PatchOutcome {
+	p := patcher.New(game.GameDir, patcherConfig(game))
+	if err := apply(p); err != nil {
+		rep.Event("patch.error", map[string]interface{}{"filename": filename, "error": err.Error()})
+		return patches
+	}
+
+	sum, size, err := sha256File(filepath.Join(game.GameDir, filename))
+	if err != nil {
+		rep.Event("patch.error", map[string]interface{}{"filename": filename, "error": err.Error()})
+		return patches
+	}
+
+	rep.Event("patch.written", map[string]interface{}{"filename": filename, "sha256": sum, "bytes": size})
+	return append(patches, PatchOutcome{Filename: filename, SHA256: sum, Bytes: size})
+}
+
+// sha256File hashes a file's contents for a PatchOutcome's sha256/bytes fields.
+func sha256File(path string) (string, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), len(data), nil
+}
+
+// runnerPoolMap runs fn over paths with n workers, each driving its own
+// *runner.Runner cloned from base, and returns outcomes indexed identically
+// to paths.
+func runnerPoolMap(base *runner.Runner, paths []string, n int, fn func(w *runner.Runner, path string) FileOutcome) []FileOutcome {
+	if n < 1 {
+		n = 1
+	}
+	if n > len(paths) {
+		n = len(paths)
+	}
+	if n < 1 {
+		return nil
+	}
+
+	out := make([]FileOutcome, len(paths))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker := base.Clone()
+			for i := range jobs {
+				out[i] = fn(worker, paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return out
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// OutputFormat selects how a single-game automation run reports its
+// progress: plain text (the interactive/legacy fmt.Println output), a
+// single aggregate JSON object printed at exit, or newline-delimited JSON
+// events streamed as work happens.
+type OutputFormat string
+
+const (
+	FormatText   OutputFormat = "text"
+	FormatJSON   OutputFormat = "json"
+	FormatNDJSON OutputFormat = "ndjson"
+)
+
+// parseOutputFormat validates the --format flag value.
+func parseOutputFormat(s string) (OutputFormat, error) {
+	switch OutputFormat(s) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatNDJSON:
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("invalid --format %q (want text, json, or ndjson)", s)
+	}
+}
+
+// Reporter receives progress events from the reported action pipeline
+// (runActionsReported and friends). TextReporter discards them, since text
+// mode's output is the handle* functions' existing fmt.Println calls;
+// NDJSONReporter streams one JSON object per event to stdout.
+type Reporter interface {
+	Event(event string, fields map[string]interface{})
+}
+
+// TextReporter discards events.
+type TextReporter struct{}
+
+func (TextReporter) Event(string, map[string]interface{}) {}
+
+// NDJSONReporter writes one compact JSON object per event to stdout as
+// work progresses, e.g. {"event":"rpa.extract.start","path":"..."}.
+type NDJSONReporter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONReporter creates a reporter that streams events to stdout.
+func NewNDJSONReporter() *NDJSONReporter {
+	return &NDJSONReporter{enc: json.NewEncoder(os.Stdout)}
+}
+
+func (r *NDJSONReporter) Event(event string, fields map[string]interface{}) {
+	line := make(map[string]interface{}, len(fields)+1)
+	line["event"] = event
+	for k, v := range fields {
+		line[k] = v
+	}
+	_ = r.enc.Encode(line)
+}
+
+// FileOutcome is one extract/decompile result, shared by the JSON and
+// NDJSON output.
+type FileOutcome struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Duration string `json:"duration"`
+	Status   string `json:"status"` // "ok", "skipped", "ignored", or "failed"
+	Error    string `json:"error,omitempty"`
+}
+
+// PatchOutcome describes one patch file written by patcher.Patcher.
+type PatchOutcome struct {
+	Filename string `json:"filename"`
+	SHA256   string `json:"sha256"`
+	Bytes    int    `json:"bytes"`
+}
+
+// RunSummary is the single top-level object emitted in --format json mode.
+type RunSummary struct {
+	Game struct {
+		Name         string `json:"name"`
+		GameDir      string `json:"game_dir"`
+		RenPyVersion int    `json:"renpy_version"`
+		Python       string `json:"python,omitempty"`
+	} `json:"game"`
+	Actions   []string       `json:"actions"`
+	Extract   []FileOutcome  `json:"extract,omitempty"`
+	Decompile []FileOutcome  `json:"decompile,omitempty"`
+	Patches   []PatchOutcome `json:"patches,omitempty"`
+}
@@ -0,0 +1,273 @@
+package rpa
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FS returns a read-only fs.FS backed by the archive's index, so callers
+// can use fs.WalkDir, http.FS, text/template.ParseFS, etc. against an RPA
+// archive without extracting it. The returned value also implements
+// fs.ReadDirFS, fs.StatFS, and fs.ReadFileFS. The index is read and the
+// directory tree built lazily on first use, then cached.
+func (a *Archive) FS() fs.FS {
+	return &archiveFS{a: a}
+}
+
+type archiveFS struct {
+	a    *Archive
+	once sync.Once
+	root *fsNode
+	err  error
+}
+
+// fsNode is one entry in the directory tree synthesized from the archive's
+// flat, "/"-separated FileEntry.Path values. entry is nil for directories.
+type fsNode struct {
+	name     string
+	entry    *FileEntry
+	children map[string]*fsNode
+}
+
+func newDirNode(name string) *fsNode {
+	return &fsNode{name: name, children: map[string]*fsNode{}}
+}
+
+func (n *fsNode) isDir() bool { return n.entry == nil }
+
+func (n *fsNode) fileInfo() fileInfo {
+	if n.entry == nil {
+		return fileInfo{name: n.name, isDir: true}
+	}
+	return fileInfo{name: n.name, size: n.entry.Length}
+}
+
+func (n *fsNode) dirEntries() []fs.DirEntry {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]fs.DirEntry, len(names))
+	for i, name := range names {
+		out[i] = dirEntry{n.children[name].fileInfo()}
+	}
+	return out
+}
+
+func (afs *archiveFS) load() error {
+	afs.once.Do(func() {
+		entries, err := afs.a.ReadIndex()
+		if err != nil {
+			afs.err = err
+			return
+		}
+
+		root := newDirNode(".")
+		for _, e := range entries {
+			insertEntry(root, e)
+		}
+		afs.root = root
+	})
+	return afs.err
+}
+
+// insertEntry walks path's "/"-separated components under root, creating
+// intermediate directory nodes as needed, and attaches entry to the leaf.
+func insertEntry(root *fsNode, entry FileEntry) {
+	parts := strings.Split(entry.Path, "/")
+	cur := root
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		child, ok := cur.children[part]
+		if !ok {
+			child = &fsNode{name: part}
+			cur.children[part] = child
+		}
+		if i == len(parts)-1 {
+			e := entry
+			child.entry = &e
+		} else if child.children == nil {
+			child.children = map[string]*fsNode{}
+		}
+		cur = child
+	}
+}
+
+// lookupNode resolves a fs.FS-valid slash-separated name against root.
+func lookupNode(root *fsNode, name string) (*fsNode, error) {
+	if name == "." {
+		return root, nil
+	}
+	cur := root
+	for _, part := range strings.Split(name, "/") {
+		if cur.children == nil {
+			return nil, fs.ErrNotExist
+		}
+		next, ok := cur.children[part]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+func (afs *archiveFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if err := afs.load(); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	node, err := lookupNode(afs.root, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if node.isDir() {
+		return &openDir{node: node}, nil
+	}
+	return afs.a.openFile(node)
+}
+
+func (afs *archiveFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	if err := afs.load(); err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	node, err := lookupNode(afs.root, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return node.fileInfo(), nil
+}
+
+func (afs *archiveFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	if err := afs.load(); err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	node, err := lookupNode(afs.root, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	if !node.isDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return node.dirEntries(), nil
+}
+
+func (afs *archiveFS) ReadFile(name string) ([]byte, error) {
+	f, err := afs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// openFile opens its own handle onto the archive so concurrent reads of
+// different entries don't race over a shared file position, and streams
+// the entry's prefix followed by its data via io.SectionReader rather than
+// buffering the whole file.
+func (a *Archive) openFile(node *fsNode) (fs.File, error) {
+	f, err := os.Open(a.path)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: node.name, Err: err}
+	}
+
+	entry := node.entry
+	contentLen := entry.Length - int64(len(entry.Prefix))
+	var r io.Reader = io.NewSectionReader(f, entry.Offset, contentLen)
+	if len(entry.Prefix) > 0 {
+		r = io.MultiReader(bytes.NewReader(entry.Prefix), r)
+	}
+
+	return &openFile{info: node.fileInfo(), r: r, f: f}, nil
+}
+
+type openFile struct {
+	info fileInfo
+	r    io.Reader
+	f    *os.File
+}
+
+func (of *openFile) Stat() (fs.FileInfo, error) { return of.info, nil }
+func (of *openFile) Read(p []byte) (int, error) { return of.r.Read(p) }
+func (of *openFile) Close() error               { return of.f.Close() }
+
+type openDir struct {
+	node    *fsNode
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (od *openDir) Stat() (fs.FileInfo, error) { return od.node.fileInfo(), nil }
+func (od *openDir) Close() error               { return nil }
+
+func (od *openDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: od.node.name, Err: errors.New("is a directory")}
+}
+
+func (od *openDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if od.entries == nil {
+		od.entries = od.node.dirEntries()
+	}
+	if n <= 0 {
+		out := od.entries[od.offset:]
+		od.offset = len(od.entries)
+		return out, nil
+	}
+	if od.offset >= len(od.entries) {
+		return nil, io.EOF
+	}
+	end := od.offset + n
+	if end > len(od.entries) {
+		end = len(od.entries)
+	}
+	out := od.entries[od.offset:end]
+	od.offset = end
+	return out, nil
+}
+
+// fileInfo is the fs.FileInfo for both files and synthesized directories.
+type fileInfo struct {
+	name  string
+	size  int64
+	isDir bool
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi fileInfo) IsDir() bool        { return fi.isDir }
+func (fi fileInfo) Sys() interface{}   { return nil }
+
+func (fi fileInfo) Mode() fs.FileMode {
+	if fi.isDir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// dirEntry adapts fileInfo to fs.DirEntry.
+type dirEntry struct {
+	fileInfo
+}
+
+func (de dirEntry) Type() fs.FileMode          { return de.Mode().Type() }
+func (de dirEntry) Info() (fs.FileInfo, error) { return de.fileInfo, nil }
@@ -0,0 +1,92 @@
+package rpa
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unren/unren-go/rpa/pickle"
+)
+
+// FuzzOpen feeds arbitrary bytes as an on-disk archive to Open+ReadIndex.
+// Neither should ever panic, regardless of how malformed the input is.
+func FuzzOpen(f *testing.F) {
+	f.Add([]byte(""))
+	f.Add([]byte("RPA-3.0 0000000000000000 00000000\n"))
+	f.Add([]byte("RPA-2.0 0000000000000010\ngarbage"))
+	f.Add([]byte("not an rpa file at all"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		path := filepath.Join(t.TempDir(), "fuzz.rpa")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("failed to write fixture: %v", err)
+		}
+
+		a, err := Open(path)
+		if err != nil {
+			return
+		}
+		_, _ = a.ReadIndex()
+	})
+}
+
+// FuzzParsePickledIndex targets the pickle VM directly with handcrafted
+// protocol 0-4 streams (memo cycles, nested tuples, oversize LONG4 and
+// BINUNICODE8 lengths) as seeds, on top of whatever real index dumps Load is
+// exercised with elsewhere.
+func FuzzParsePickledIndex(f *testing.F) {
+	f.Add([]byte{0x80, 0x02, '}', '.'})                            // empty dict
+	f.Add([]byte{0x80, 0x02, 'h', 0x00, '.'})                      // BINGET of unset memo
+	f.Add([]byte{0x80, 0x02, '(', '(', '(', 't', 't', 't', '.'})   // nested empty tuples
+	f.Add([]byte{0x80, 0x02, 0x8b, 0xff, 0xff, 0xff, 0x7f})        // LONG4 claiming ~2GB, then EOF
+	f.Add([]byte{0x80, 0x02, '}', '(', 'K', 0x01, 'K', 0x02, 'u'}) // dict with one pair, no STOP
+	f.Add([]byte{0x80, 0x02, 0x8d, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00}) // BINUNICODE8 claiming ~256TiB, then EOF
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = pickle.Load(bytes.NewReader(data))
+	})
+}
+
+// FuzzExtract drives ExtractFiles with a fuzzed entry path and asserts that
+// nothing is ever written outside the destination directory, exercising
+// secureJoin against path-traversal attempts.
+func FuzzExtract(f *testing.F) {
+	f.Add("normal.txt")
+	f.Add("../evil.txt")
+	f.Add("..\\evil.txt")
+	f.Add("a/../../evil.txt")
+	f.Add("/etc/passwd")
+	f.Add("sub/dir/file.txt")
+
+	f.Fuzz(func(t *testing.T, name string) {
+		dir := t.TempDir()
+
+		archivePath := filepath.Join(dir, "a.rpa")
+		if err := os.WriteFile(archivePath, bytes.Repeat([]byte{0x41}, 16), 0644); err != nil {
+			t.Fatalf("failed to write fixture archive: %v", err)
+		}
+
+		destDir := filepath.Join(dir, "dest")
+		if err := os.MkdirAll(destDir, 0755); err != nil {
+			t.Fatalf("failed to create dest dir: %v", err)
+		}
+
+		a := &Archive{path: archivePath, version: 2}
+		entries := []FileEntry{{Path: name, Offset: 0, Length: 4}}
+
+		_, _ = a.ExtractFiles(entries, destDir)
+
+		_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || path == archivePath {
+				return nil
+			}
+			rel, relErr := filepath.Rel(destDir, path)
+			if relErr != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				t.Fatalf("file written outside destination directory: %s", path)
+			}
+			return nil
+		})
+	})
+}
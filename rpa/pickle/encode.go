@@ -0,0 +1,261 @@
+package pickle
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+)
+
+// Dump encodes v as a pickle protocol 2 stream, the inverse of Load for the
+// subset of values Load can produce (nil, bool, string, integers, Tuple,
+// []interface{}, Dict). It also accepts []byte, written as a pickled byte
+// string that Load decodes back as a string. It does not memoize repeated
+// values - Ren'Py unpickles the result fine either way, and skipping the
+// memo table keeps the encoder a straightforward mirror of Load's opcodes
+// rather than a second stack machine.
+func Dump(w io.Writer, v interface{}) error {
+	bw := bufio.NewWriter(w)
+
+	if err := bw.WriteByte(0x80); err != nil { // PROTO
+		return err
+	}
+	if err := bw.WriteByte(2); err != nil {
+		return err
+	}
+
+	if err := encodeValue(bw, v); err != nil {
+		return err
+	}
+
+	if err := bw.WriteByte('.'); err != nil { // STOP
+		return err
+	}
+	return bw.Flush()
+}
+
+func encodeValue(w *bufio.Writer, v interface{}) error {
+	switch val := v.(type) {
+	case nil:
+		return w.WriteByte('N')
+
+	case bool:
+		if val {
+			return w.WriteByte(0x88) // NEWTRUE
+		}
+		return w.WriteByte(0x89) // NEWFALSE
+
+	case string:
+		return encodeString(w, val)
+
+	case []byte:
+		return encodeBytes(w, val)
+
+	case int:
+		return encodeInt(w, int64(val))
+
+	case int64:
+		return encodeInt(w, val)
+
+	case *big.Int:
+		return encodeBigInt(w, val)
+
+	case Tuple:
+		return encodeTuple(w, []interface{}(val))
+
+	case []interface{}:
+		return encodeList(w, val)
+
+	case Dict:
+		return encodeDict(w, val)
+
+	default:
+		return fmt.Errorf("pickle: cannot encode value of type %T", v)
+	}
+}
+
+// encodeString emits a BINUNICODE opcode; it is valid from protocol 1
+// onward, so it covers the protocol-2 streams Dump produces.
+func encodeString(w *bufio.Writer, s string) error {
+	if err := w.WriteByte('X'); err != nil {
+		return err
+	}
+	return writeUint32AndBytes(w, []byte(s))
+}
+
+// encodeBytes emits the pickled string opcodes, used for RPA prefixes.
+func encodeBytes(w *bufio.Writer, b []byte) error {
+	if len(b) <= 0xff {
+		if err := w.WriteByte('U'); err != nil { // SHORT_BINSTRING
+			return err
+		}
+		if err := w.WriteByte(byte(len(b))); err != nil {
+			return err
+		}
+		_, err := w.Write(b)
+		return err
+	}
+	if err := w.WriteByte('T'); err != nil { // BINSTRING
+		return err
+	}
+	return writeUint32AndBytes(w, b)
+}
+
+func writeUint32AndBytes(w *bufio.Writer, b []byte) error {
+	var lenBuf [4]byte
+	binary.LittleEndian.PutUint32(lenBuf[:], uint32(len(b)))
+	if _, err := w.Write(lenBuf[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func encodeInt(w *bufio.Writer, n int64) error {
+	switch {
+	case n >= 0 && n <= 0xff:
+		if err := w.WriteByte('K'); err != nil { // BININT1
+			return err
+		}
+		return w.WriteByte(byte(n))
+
+	case n >= 0 && n <= 0xffff:
+		if err := w.WriteByte('M'); err != nil { // BININT2
+			return err
+		}
+		var buf [2]byte
+		binary.LittleEndian.PutUint16(buf[:], uint16(n))
+		_, err := w.Write(buf[:])
+		return err
+
+	case n >= math.MinInt32 && n <= math.MaxInt32:
+		if err := w.WriteByte('J'); err != nil { // BININT
+			return err
+		}
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], uint32(int32(n)))
+		_, err := w.Write(buf[:])
+		return err
+
+	default:
+		return encodeBigInt(w, big.NewInt(n))
+	}
+}
+
+// encodeBigInt emits a LONG1 opcode holding n's little-endian two's
+// complement representation, the inverse of machine.readLong.
+func encodeBigInt(w *bufio.Writer, n *big.Int) error {
+	b := longBytes(n)
+	if len(b) > 0xff {
+		return fmt.Errorf("pickle: integer too large to encode as LONG1 (%d bytes)", len(b))
+	}
+	if err := w.WriteByte(0x8a); err != nil { // LONG1
+		return err
+	}
+	if err := w.WriteByte(byte(len(b))); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// longBytes returns n's minimal little-endian two's complement encoding,
+// the byte layout LONG1/LONG4 expect.
+func longBytes(n *big.Int) []byte {
+	if n.Sign() == 0 {
+		return nil
+	}
+
+	neg := n.Sign() < 0
+	mag := new(big.Int).Abs(n)
+
+	nbytes := (mag.BitLen() / 8) + 1
+	be := mag.Bytes()
+	// left-pad to nbytes
+	padded := make([]byte, nbytes)
+	copy(padded[nbytes-len(be):], be)
+
+	if neg {
+		twos := new(big.Int).Lsh(big.NewInt(1), uint(nbytes*8))
+		twos.Sub(twos, mag)
+		be = twos.Bytes()
+		padded = make([]byte, nbytes)
+		copy(padded[nbytes-len(be):], be)
+	}
+
+	// reverse to little-endian
+	le := make([]byte, nbytes)
+	for i, v := range padded {
+		le[nbytes-1-i] = v
+	}
+
+	// drop a redundant leading (i.e. trailing, in little-endian) sign byte
+	for len(le) > 1 {
+		last := le[len(le)-1]
+		if neg && last == 0xff && le[len(le)-2]&0x80 != 0 {
+			le = le[:len(le)-1]
+			continue
+		}
+		if !neg && last == 0x00 && le[len(le)-2]&0x80 == 0 {
+			le = le[:len(le)-1]
+			continue
+		}
+		break
+	}
+	return le
+}
+
+// encodeTuple emits MARK, each item, then TUPLE - valid for any arity,
+// unlike the fixed TUPLE1/TUPLE2/TUPLE3 opcodes Load also understands.
+func encodeTuple(w *bufio.Writer, items []interface{}) error {
+	if err := w.WriteByte('('); err != nil { // MARK
+		return err
+	}
+	for _, item := range items {
+		if err := encodeValue(w, item); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('t') // TUPLE
+}
+
+func encodeList(w *bufio.Writer, items []interface{}) error {
+	if err := w.WriteByte(']'); err != nil { // EMPTY_LIST
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	if err := w.WriteByte('('); err != nil { // MARK
+		return err
+	}
+	for _, item := range items {
+		if err := encodeValue(w, item); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('e') // APPENDS
+}
+
+func encodeDict(w *bufio.Writer, d Dict) error {
+	if err := w.WriteByte('}'); err != nil { // EMPTY_DICT
+		return err
+	}
+	if len(d) == 0 {
+		return nil
+	}
+	if err := w.WriteByte('('); err != nil { // MARK
+		return err
+	}
+	for k, v := range d {
+		if err := encodeValue(w, k); err != nil {
+			return err
+		}
+		if err := encodeValue(w, v); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('u') // SETITEMS
+}
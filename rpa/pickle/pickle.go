@@ -0,0 +1,497 @@
+// Package pickle implements a minimal interpreter for the subset of the
+// Python pickle protocol (versions 0-4) that Ren'Py's RPA index uses: a
+// stack + memo machine that decodes nested dicts/lists/tuples of strings
+// and integers. It is not a general-purpose unpickler - opcodes that
+// pickle never emits for plain data (GLOBAL, REDUCE, BUILD, class
+// instances, etc.) are intentionally unsupported and return an error.
+package pickle
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// maxLongBytes bounds LONG1/LONG4's declared byte count, so a corrupt or
+// hostile index can't make us allocate an unreasonable integer.
+const maxLongBytes = 1 << 16
+
+// maxStringBytes bounds BINUNICODE/BINUNICODE8/BINSTRING's declared byte
+// count. These opcodes carry a 32- or 64-bit attacker-controlled length that
+// readN would otherwise make(...) before ever reading a byte of payload, so
+// a single crafted length (e.g. 0xFFFFFFFFFFFFFFFF) triggers a fatal
+// out-of-memory abort - not a catchable error - well before io.ReadFull ever
+// fails. 16 MiB is far more than any real RPA index entry needs.
+const maxStringBytes = 1 << 24
+
+// mark is the sentinel pushed by the MARK opcode and popped back to by
+// TUPLE/DICT/LIST/APPENDS/SETITEMS, mirroring CPython's markobject.
+type mark struct{}
+
+// Dict is the decoded form of a pickled dict. Ren'Py only ever pickles
+// string keys for the RPA index, but the type accepts any comparable key
+// pickle can produce.
+type Dict map[interface{}]interface{}
+
+// Tuple is the decoded form of a pickled tuple, kept distinct from a plain
+// []interface{} list since RPA index values are specifically tuples.
+type Tuple []interface{}
+
+// Load decodes a single pickled value from r, stopping at the first STOP
+// opcode (protocol 0-4).
+func Load(r io.Reader) (interface{}, error) {
+	br := bufio.NewReader(r)
+	vm := &machine{r: br, memo: map[int]interface{}{}}
+	return vm.run()
+}
+
+type machine struct {
+	r     *bufio.Reader
+	stack []interface{}
+	memo  map[int]interface{}
+}
+
+func (m *machine) push(v interface{}) { m.stack = append(m.stack, v) }
+
+func (m *machine) pop() (interface{}, error) {
+	if len(m.stack) == 0 {
+		return nil, fmt.Errorf("pickle: stack underflow")
+	}
+	v := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	return v, nil
+}
+
+func (m *machine) peek() (interface{}, error) {
+	if len(m.stack) == 0 {
+		return nil, fmt.Errorf("pickle: stack underflow")
+	}
+	return m.stack[len(m.stack)-1], nil
+}
+
+// popToMark pops everything above (and including) the most recent mark,
+// returning the popped items in original order.
+func (m *machine) popToMark() ([]interface{}, error) {
+	for i := len(m.stack) - 1; i >= 0; i-- {
+		if _, ok := m.stack[i].(mark); ok {
+			items := append([]interface{}{}, m.stack[i+1:]...)
+			m.stack = m.stack[:i]
+			return items, nil
+		}
+	}
+	return nil, fmt.Errorf("pickle: no matching mark")
+}
+
+func (m *machine) readByte() (byte, error) { return m.r.ReadByte() }
+
+func (m *machine) readN(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(m.r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (m *machine) readUint32() (uint32, error) {
+	b, err := m.readN(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func (m *machine) run() (interface{}, error) {
+	for {
+		op, err := m.readByte()
+		if err != nil {
+			return nil, fmt.Errorf("pickle: unexpected end of stream: %w", err)
+		}
+
+		switch op {
+		case 0x80: // PROTO
+			if _, err := m.readByte(); err != nil {
+				return nil, err
+			}
+
+		case 0x95: // FRAME
+			if _, err := m.readN(8); err != nil {
+				return nil, err
+			}
+
+		case '(': // MARK
+			m.push(mark{})
+
+		case '.': // STOP
+			return m.pop()
+
+		case 'N': // NONE
+			m.push(nil)
+
+		case 0x88: // NEWTRUE
+			m.push(true)
+
+		case 0x89: // NEWFALSE
+			m.push(false)
+
+		case '}': // EMPTY_DICT
+			m.push(Dict{})
+
+		case ']': // EMPTY_LIST
+			m.push([]interface{}{})
+
+		case 'd': // DICT
+			items, err := m.popToMark()
+			if err != nil {
+				return nil, err
+			}
+			d, err := pairsToDict(items)
+			if err != nil {
+				return nil, err
+			}
+			m.push(d)
+
+		case 'l': // LIST
+			items, err := m.popToMark()
+			if err != nil {
+				return nil, err
+			}
+			m.push(items)
+
+		case 'e': // APPENDS
+			items, err := m.popToMark()
+			if err != nil {
+				return nil, err
+			}
+			top, err := m.pop()
+			if err != nil {
+				return nil, err
+			}
+			list, ok := top.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("pickle: APPENDS onto non-list")
+			}
+			m.push(append(list, items...))
+
+		case 'a': // APPEND
+			item, err := m.pop()
+			if err != nil {
+				return nil, err
+			}
+			top, err := m.pop()
+			if err != nil {
+				return nil, err
+			}
+			list, ok := top.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("pickle: APPEND onto non-list")
+			}
+			m.push(append(list, item))
+
+		case 't': // TUPLE
+			items, err := m.popToMark()
+			if err != nil {
+				return nil, err
+			}
+			m.push(Tuple(items))
+
+		case 0x85: // TUPLE1
+			if err := m.makeTuple(1); err != nil {
+				return nil, err
+			}
+
+		case 0x86: // TUPLE2
+			if err := m.makeTuple(2); err != nil {
+				return nil, err
+			}
+
+		case 0x87: // TUPLE3
+			if err := m.makeTuple(3); err != nil {
+				return nil, err
+			}
+
+		case 0x8c: // SHORT_BINUNICODE
+			n, err := m.readByte()
+			if err != nil {
+				return nil, err
+			}
+			b, err := m.readN(int(n))
+			if err != nil {
+				return nil, err
+			}
+			m.push(string(b))
+
+		case 'X': // BINUNICODE
+			n, err := m.readUint32()
+			if err != nil {
+				return nil, err
+			}
+			if n > maxStringBytes {
+				return nil, fmt.Errorf("pickle: BINUNICODE length %d exceeds %d byte limit", n, maxStringBytes)
+			}
+			b, err := m.readN(int(n))
+			if err != nil {
+				return nil, err
+			}
+			m.push(string(b))
+
+		case 0x8d: // BINUNICODE8
+			b8, err := m.readN(8)
+			if err != nil {
+				return nil, err
+			}
+			n := binary.LittleEndian.Uint64(b8)
+			if n > maxStringBytes {
+				return nil, fmt.Errorf("pickle: BINUNICODE8 length %d exceeds %d byte limit", n, maxStringBytes)
+			}
+			b, err := m.readN(int(n))
+			if err != nil {
+				return nil, err
+			}
+			m.push(string(b))
+
+		case 'U': // SHORT_BINSTRING
+			n, err := m.readByte()
+			if err != nil {
+				return nil, err
+			}
+			b, err := m.readN(int(n))
+			if err != nil {
+				return nil, err
+			}
+			m.push(string(b))
+
+		case 'T': // BINSTRING
+			n, err := m.readUint32()
+			if err != nil {
+				return nil, err
+			}
+			if n > maxStringBytes {
+				return nil, fmt.Errorf("pickle: BINSTRING length %d exceeds %d byte limit", n, maxStringBytes)
+			}
+			b, err := m.readN(int(n))
+			if err != nil {
+				return nil, err
+			}
+			m.push(string(b))
+
+		case 'J': // BININT
+			b, err := m.readN(4)
+			if err != nil {
+				return nil, err
+			}
+			m.push(int64(int32(binary.LittleEndian.Uint32(b))))
+
+		case 'K': // BININT1
+			b, err := m.readByte()
+			if err != nil {
+				return nil, err
+			}
+			m.push(int64(b))
+
+		case 'M': // BININT2
+			b, err := m.readN(2)
+			if err != nil {
+				return nil, err
+			}
+			m.push(int64(binary.LittleEndian.Uint16(b)))
+
+		case 0x8a: // LONG1
+			n, err := m.readByte()
+			if err != nil {
+				return nil, err
+			}
+			v, err := m.readLong(int(n))
+			if err != nil {
+				return nil, err
+			}
+			m.push(v)
+
+		case 0x8b: // LONG4
+			n, err := m.readUint32()
+			if err != nil {
+				return nil, err
+			}
+			if n > maxLongBytes {
+				return nil, fmt.Errorf("pickle: LONG4 length %d exceeds %d byte limit", n, maxLongBytes)
+			}
+			v, err := m.readLong(int(n))
+			if err != nil {
+				return nil, err
+			}
+			m.push(v)
+
+		case 'h': // BINGET
+			idx, err := m.readByte()
+			if err != nil {
+				return nil, err
+			}
+			v, ok := m.memo[int(idx)]
+			if !ok {
+				return nil, fmt.Errorf("pickle: BINGET of unset memo %d", idx)
+			}
+			m.push(v)
+
+		case 'j': // LONG_BINGET
+			idx, err := m.readUint32()
+			if err != nil {
+				return nil, err
+			}
+			v, ok := m.memo[int(idx)]
+			if !ok {
+				return nil, fmt.Errorf("pickle: LONG_BINGET of unset memo %d", idx)
+			}
+			m.push(v)
+
+		case 'q': // BINPUT
+			idx, err := m.readByte()
+			if err != nil {
+				return nil, err
+			}
+			v, err := m.peek()
+			if err != nil {
+				return nil, err
+			}
+			m.memo[int(idx)] = v
+
+		case 'r': // LONG_BINPUT
+			idx, err := m.readUint32()
+			if err != nil {
+				return nil, err
+			}
+			v, err := m.peek()
+			if err != nil {
+				return nil, err
+			}
+			m.memo[int(idx)] = v
+
+		case 0x94: // MEMOIZE
+			v, err := m.peek()
+			if err != nil {
+				return nil, err
+			}
+			m.memo[len(m.memo)] = v
+
+		case 's': // SETITEM
+			value, err := m.pop()
+			if err != nil {
+				return nil, err
+			}
+			key, err := m.pop()
+			if err != nil {
+				return nil, err
+			}
+			top, err := m.peek()
+			if err != nil {
+				return nil, err
+			}
+			d, ok := top.(Dict)
+			if !ok {
+				return nil, fmt.Errorf("pickle: SETITEM onto non-dict")
+			}
+			if !isHashableKey(key) {
+				return nil, fmt.Errorf("pickle: unhashable dict key of type %T", key)
+			}
+			d[key] = value
+
+		case 'u': // SETITEMS
+			items, err := m.popToMark()
+			if err != nil {
+				return nil, err
+			}
+			top, err := m.peek()
+			if err != nil {
+				return nil, err
+			}
+			d, ok := top.(Dict)
+			if !ok {
+				return nil, fmt.Errorf("pickle: SETITEMS onto non-dict")
+			}
+			pairs, err := pairsToDict(items)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range pairs {
+				d[k] = v
+			}
+
+		default:
+			return nil, fmt.Errorf("pickle: unsupported opcode 0x%02x", op)
+		}
+	}
+}
+
+// makeTuple pops exactly n items and pushes them as a Tuple, for the fixed-
+// arity TUPLE1/TUPLE2/TUPLE3 opcodes.
+func (m *machine) makeTuple(n int) error {
+	if len(m.stack) < n {
+		return fmt.Errorf("pickle: stack underflow building tuple of %d", n)
+	}
+	items := append([]interface{}{}, m.stack[len(m.stack)-n:]...)
+	m.stack = m.stack[:len(m.stack)-n]
+	m.push(Tuple(items))
+	return nil
+}
+
+// readLong decodes an n-byte little-endian two's-complement integer
+// (LONG1/LONG4's payload), returning an int64 when it fits and a *big.Int
+// otherwise.
+func (m *machine) readLong(n int) (interface{}, error) {
+	if n == 0 {
+		return int64(0), nil
+	}
+	b, err := m.readN(n)
+	if err != nil {
+		return nil, err
+	}
+
+	negative := b[n-1]&0x80 != 0
+
+	be := make([]byte, n)
+	for i, v := range b {
+		be[n-1-i] = v
+	}
+
+	v := new(big.Int).SetBytes(be)
+	if negative {
+		max := new(big.Int).Lsh(big.NewInt(1), uint(n*8))
+		v.Sub(v, max)
+	}
+
+	if v.IsInt64() {
+		return v.Int64(), nil
+	}
+	return v, nil
+}
+
+// isHashableKey reports whether v is one of the dict key types this VM
+// actually supports (string, int64, bool, or nil) - the only ones Ren'Py's
+// RPA index ever uses. Go maps panic on insert if the key's dynamic type
+// isn't comparable (e.g. a Tuple or []interface{}), so SETITEM/pairsToDict
+// must reject anything else up front instead of letting that panic happen.
+func isHashableKey(v interface{}) bool {
+	switch v.(type) {
+	case nil, string, int64, bool:
+		return true
+	default:
+		return false
+	}
+}
+
+// pairsToDict folds a flat [k1, v1, k2, v2, ...] slice (as produced by
+// MARK...DICT/SETITEMS) into a Dict.
+func pairsToDict(items []interface{}) (Dict, error) {
+	if len(items)%2 != 0 {
+		return nil, fmt.Errorf("pickle: odd number of dict items (%d)", len(items))
+	}
+	d := make(Dict, len(items)/2)
+	for i := 0; i < len(items); i += 2 {
+		key := items[i]
+		if !isHashableKey(key) {
+			return nil, fmt.Errorf("pickle: unhashable dict key of type %T", key)
+		}
+		d[key] = items[i+1]
+	}
+	return d, nil
+}
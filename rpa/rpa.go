@@ -6,19 +6,30 @@ import (
 	"bufio"
 	"bytes"
 	"compress/zlib"
-	"encoding/binary"
+	"context"
 	"fmt"
 	"io"
+	"math/big"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+
+	"github.com/unren/unren-go/rpa/pickle"
 )
 
 // MaxIndexSize limits the index data size to prevent memory exhaustion.
 // Most RPA indexes are under 10MB even for large archives.
 const MaxIndexSize = 50 * 1024 * 1024 // 50 MB
 
+// MaxDecompressionRatio bounds how much larger a decompressed index (or, in
+// the future, a decompressed per-file payload) may be than its compressed
+// form, in addition to the absolute MaxIndexSize cap. This is what actually
+// stops a zlib bomb: a tiny compressed index that claims to decompress to
+// just under MaxIndexSize is still rejected if it blows past this ratio.
+const MaxDecompressionRatio = 1024
+
 // BufferSize for streaming file extraction.
 const BufferSize = 64 * 1024 // 64 KB
 
@@ -157,154 +168,106 @@ func (a *Archive) ReadIndex() ([]FileEntry, error) {
 	}
 	defer zlibReader.Close()
 
-	// Limit decompressed size (indexes typically decompress to ~2-5x size)
-	limitReader := io.LimitReader(zlibReader, MaxIndexSize)
+	// Bound decompressed size by both the absolute MaxIndexSize and
+	// MaxDecompressionRatio relative to the compressed size, so a small
+	// compressed index claiming a huge decompressed size (a zlib bomb)
+	// is rejected even when it's still under MaxIndexSize.
+	limit := int64(MaxIndexSize)
+	if ratioLimit := int64(len(compressedData)) * MaxDecompressionRatio; ratioLimit < limit {
+		limit = ratioLimit
+	}
+
+	limitReader := io.LimitReader(zlibReader, limit+1)
 	indexData, err := io.ReadAll(limitReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decompress index: %w", err)
 	}
+	if int64(len(indexData)) > limit {
+		return nil, fmt.Errorf("index exceeds decompression limit (%d bytes, ratio %dx)", limit, MaxDecompressionRatio)
+	}
 
 	// Free compressed data early
 	compressedData = nil
 
 	// Parse the pickle format
-	return a.parsePickledIndex(indexData)
-}
-
-// parsePickledIndex parses a Python pickle-encoded index.
-// This is a simplified parser that handles the common RPA index format.
-func (a *Archive) parsePickledIndex(data []byte) ([]FileEntry, error) {
-	// Check for pickle protocol marker
-	if len(data) == 0 {
-		return nil, fmt.Errorf("empty index data")
+	value, err := pickle.Load(bytes.NewReader(indexData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse index: %w", err)
 	}
 
-	offset := 0
-	// Protocol 2 starts with 0x80 0x02
-	if data[0] == 0x80 && len(data) > 1 {
-		offset = 2
+	return a.decodeIndex(value)
+}
+
+// decodeIndex converts the pickle.Load result of an RPA index - a dict
+// mapping each archive path to a one-element list of (offset, length[,
+// prefix]) tuples - into FileEntries, applying the RPA-3.0 XOR key to the
+// offset and length where applicable.
+func (a *Archive) decodeIndex(value interface{}) ([]FileEntry, error) {
+	dict, ok := value.(pickle.Dict)
+	if !ok {
+		return nil, fmt.Errorf("rpa index: expected a dict at top level, got %T", value)
 	}
 
-	// Parse the pickled data structure
-	return a.parsePickleDict(data[offset:])
-}
+	entries := make([]FileEntry, 0, len(dict))
+	for k, v := range dict {
+		path, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("rpa index: non-string path key %v", k)
+		}
 
-// parsePickleDict parses a pickled dictionary for file entries.
-func (a *Archive) parsePickleDict(data []byte) ([]FileEntry, error) {
-	entries := make([]FileEntry, 0, 100) // Pre-allocate reasonable capacity
-
-	// Find string markers and extract filenames and tuples
-	// This is a heuristic approach for common RPA formats
-	i := 0
-	dataLen := len(data)
-	for i < dataLen {
-		// Look for pickle string opcodes
-		switch data[i] {
-		case 'U': // SHORT_BINSTRING
-			if i+1 >= dataLen {
-				i++
-				continue
-			}
-			strLen := int(data[i+1])
-			if strLen <= 0 || i+2+strLen > dataLen {
-				i++
-				continue
-			}
-			filename := string(data[i+2 : i+2+strLen])
-
-			// Check if this looks like a valid filename
-			if isValidFilename(filename) {
-				// Search for the associated tuple data (offset, length)
-				remaining := dataLen - (i + 2 + strLen)
-				if remaining > 100 {
-					remaining = 100
-				}
-				entry, found := a.findFileData(data[i+2+strLen:i+2+strLen+remaining], filename)
-				if found {
-					entries = append(entries, entry)
-				}
-			}
-			i += 2 + strLen
+		list, ok := v.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, fmt.Errorf("rpa index: %s: expected a non-empty list of tuples", path)
+		}
+		tup, ok := list[0].(pickle.Tuple)
+		if !ok || len(tup) < 2 {
+			return nil, fmt.Errorf("rpa index: %s: expected an (offset, length[, prefix]) tuple", path)
+		}
 
-		case 'X': // BINUNICODE
-			if i+4 >= dataLen {
-				i++
-				continue
-			}
-			strLen := int(binary.LittleEndian.Uint32(data[i+1:]))
-			if strLen <= 0 || strLen > 1000 || i+5+strLen > dataLen {
-				i++
-				continue
-			}
-			filename := string(data[i+5 : i+5+strLen])
+		offset, err := toInt64(tup[0])
+		if err != nil {
+			return nil, fmt.Errorf("rpa index: %s: offset: %w", path, err)
+		}
+		length, err := toInt64(tup[1])
+		if err != nil {
+			return nil, fmt.Errorf("rpa index: %s: length: %w", path, err)
+		}
 
-			if isValidFilename(filename) {
-				remaining := dataLen - (i + 5 + strLen)
-				if remaining > 100 {
-					remaining = 100
-				}
-				entry, found := a.findFileData(data[i+5+strLen:i+5+strLen+remaining], filename)
-				if found {
-					entries = append(entries, entry)
-				}
+		entry := FileEntry{
+			Path:   path,
+			Offset: a.deobfuscate(offset),
+			Length: a.deobfuscate(length),
+		}
+		if len(tup) >= 3 {
+			switch prefix := tup[2].(type) {
+			case string:
+				entry.Prefix = []byte(prefix)
+			case []byte:
+				entry.Prefix = prefix
 			}
-			i += 5 + strLen
-
-		default:
-			i++
 		}
+
+		entries = append(entries, entry)
 	}
 
 	return entries, nil
 }
 
-// findFileData attempts to find offset and length data after a filename.
-func (a *Archive) findFileData(data []byte, filename string) (FileEntry, bool) {
-	entry := FileEntry{Path: filename}
-	dataLen := len(data)
-
-	// Look for tuple markers and integer data
-	// Common patterns: BININT, BININT1, BININT2, etc.
-	for i := 0; i < dataLen && i < 100; i++ {
-		switch data[i] {
-		case 'J': // BININT (4 bytes signed)
-			if i+4 < dataLen {
-				val := int64(binary.LittleEndian.Uint32(data[i+1:]))
-				if entry.Offset == 0 && val > 0 {
-					entry.Offset = a.deobfuscate(val)
-				} else if entry.Length == 0 && val > 0 {
-					entry.Length = a.deobfuscate(val)
-					return entry, true
-				}
-			}
-		case 'K': // BININT1 (1 byte unsigned)
-			if i+1 < dataLen {
-				val := int64(data[i+1])
-				if entry.Offset == 0 && val > 0 {
-					entry.Offset = a.deobfuscate(val)
-				} else if entry.Length == 0 {
-					entry.Length = a.deobfuscate(val)
-					if entry.Offset > 0 {
-						return entry, true
-					}
-				}
-			}
-		case 'M': // BININT2 (2 bytes unsigned)
-			if i+2 < dataLen {
-				val := int64(binary.LittleEndian.Uint16(data[i+1:]))
-				if entry.Offset == 0 && val > 0 {
-					entry.Offset = a.deobfuscate(val)
-				} else if entry.Length == 0 {
-					entry.Length = a.deobfuscate(val)
-					if entry.Offset > 0 {
-						return entry, true
-					}
-				}
-			}
+// toInt64 extracts an int64 from a pickle.Load result for an index
+// offset/length, which is always a BININT-family value or (rarely, for
+// huge archives) a LONG1/LONG4 decoded as *big.Int.
+func toInt64(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case *big.Int:
+		if !n.IsInt64() {
+			return 0, fmt.Errorf("value %s does not fit in int64", n.String())
 		}
+		return n.Int64(), nil
+	default:
+		return 0, fmt.Errorf("expected an integer, got %T", v)
 	}
-
-	return entry, false
 }
 
 // deobfuscate applies the RPA-3.0 key to an offset/length value.
@@ -315,112 +278,192 @@ func (a *Archive) deobfuscate(val int64) int64 {
 	return val
 }
 
-// isValidFilename checks if a string looks like a valid archive path.
-func isValidFilename(s string) bool {
-	if len(s) < 3 || len(s) > 500 {
-		return false
-	}
+// secureJoin joins destDir and name, rejecting any result that would land
+// outside destDir. filepath.Clean+HasPrefix is not reliable here (it can be
+// fooled by "..xyz" prefixes or, on Windows, by case-insensitive volumes
+// and "\\?\" paths), so this checks via filepath.Rel instead, matching how
+// archive/zip's ErrInsecurePath check works.
+func secureJoin(destDir, name string) (string, error) {
+	joined := filepath.Join(destDir, name)
 
-	// Must contain a file extension
-	if !strings.Contains(s, ".") {
-		return false
+	rel, err := filepath.Rel(destDir, joined)
+	if err != nil {
+		return "", fmt.Errorf("insecure path: %w", err)
 	}
-
-	// Common archive content extensions
-	validExts := []string{
-		".png", ".jpg", ".jpeg", ".webp", ".gif",
-		".ogg", ".mp3", ".wav", ".opus",
-		".rpy", ".rpyc", ".rpym", ".rpymc",
-		".ttf", ".otf",
-		".txt", ".json", ".yaml", ".yml",
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("insecure path: escapes destination directory")
 	}
 
-	ext := strings.ToLower(filepath.Ext(s))
-	for _, valid := range validExts {
-		if ext == valid {
-			return true
-		}
-	}
+	return joined, nil
+}
 
-	return false
+// ExtractOptions configures an ExtractAllCtx/ExtractFilesCtx call: progress
+// callbacks and how many files to extract concurrently.
+type ExtractOptions struct {
+	// OnFileStart is called on the worker goroutine handling entry, just
+	// before extraction begins. index/total describe entry's position in
+	// the batch, not extraction order.
+	OnFileStart func(entry FileEntry, index, total int)
+	// OnFileProgress is called periodically during extraction of entry.
+	OnFileProgress func(entry FileEntry, bytesDone, bytesTotal int64)
+	// OnFileDone is called once extraction of entry finishes, err nil on
+	// success (including the already-exists skip case).
+	OnFileDone func(entry FileEntry, err error)
+	// Parallelism is the number of files to extract concurrently. Values
+	// less than 1 behave as 1.
+	Parallelism int
 }
 
 // ExtractAll extracts all files from the archive to the destination directory.
 func (a *Archive) ExtractAll(destDir string) (*ExtractResult, error) {
+	return a.ExtractAllCtx(context.Background(), destDir, ExtractOptions{})
+}
+
+// ExtractAllCtx is ExtractAll with cancellation and progress reporting; see
+// ExtractFilesCtx.
+func (a *Archive) ExtractAllCtx(ctx context.Context, destDir string, opts ExtractOptions) (*ExtractResult, error) {
 	entries, err := a.ReadIndex()
 	if err != nil {
 		return nil, err
 	}
 
-	return a.ExtractFiles(entries, destDir)
+	return a.ExtractFilesCtx(ctx, entries, destDir, opts)
 }
 
 // ExtractFiles extracts specific file entries to the destination directory.
 // Uses streaming to avoid loading large files entirely into memory.
 func (a *Archive) ExtractFiles(entries []FileEntry, destDir string) (*ExtractResult, error) {
-	result := &ExtractResult{}
+	return a.ExtractFilesCtx(context.Background(), entries, destDir, ExtractOptions{})
+}
 
-	f, err := os.Open(a.path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open archive: %w", err)
+// ExtractFilesCtx is ExtractFiles with cancellation and progress reporting.
+// Up to opts.Parallelism files are extracted at once, each worker opening
+// its own handle onto the archive so concurrent reads don't race over a
+// shared file position. ctx is checked between buffer reads so a large
+// file's extraction can be cancelled promptly.
+func (a *Archive) ExtractFilesCtx(ctx context.Context, entries []FileEntry, destDir string, opts ExtractOptions) (*ExtractResult, error) {
+	result := &ExtractResult{}
+	if len(entries) == 0 {
+		return result, nil
 	}
-	defer f.Close()
 
-	// Get archive file size for validation
-	stat, err := f.Stat()
+	stat, err := os.Stat(a.path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to stat archive: %w", err)
 	}
 	archiveSize := stat.Size()
 
-	// Reusable buffer for streaming
-	buf := make([]byte, BufferSize)
+	n := opts.Parallelism
+	if n < 1 {
+		n = 1
+	}
+	if n > len(entries) {
+		n = len(entries)
+	}
 
-	for _, entry := range entries {
-		// Validate entry
-		if entry.Offset < 0 || entry.Length <= 0 {
-			result.Errors = append(result.Errors, fmt.Errorf("%s: invalid offset/length", entry.Path))
-			continue
-		}
-		if entry.Offset+entry.Length > archiveSize {
-			result.Errors = append(result.Errors, fmt.Errorf("%s: entry extends beyond archive", entry.Path))
-			continue
+	type job struct {
+		index int
+		entry FileEntry
+	}
+	jobs := make(chan job)
+
+	var mu sync.Mutex
+	record := func(extracted, skipped bool, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case err != nil:
+			result.Errors = append(result.Errors, err)
+		case skipped:
+			result.Skipped++
+		case extracted:
+			result.Extracted++
 		}
+	}
 
-		outPath := filepath.Join(destDir, entry.Path)
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
 
-		// Security: prevent path traversal
-		if !strings.HasPrefix(filepath.Clean(outPath), filepath.Clean(destDir)) {
-			result.Errors = append(result.Errors, fmt.Errorf("%s: path traversal detected", entry.Path))
-			continue
-		}
+			f, err := os.Open(a.path)
+			if err != nil {
+				for j := range jobs {
+					record(false, false, fmt.Errorf("%s: failed to open archive: %w", j.entry.Path, err))
+				}
+				return
+			}
+			defer f.Close()
 
-		// Check if file already exists
-		if _, err := os.Stat(outPath); err == nil {
-			result.Skipped++
-			continue
-		}
+			buf := make([]byte, BufferSize)
+			for j := range jobs {
+				entry := j.entry
 
-		// Create parent directories
-		if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", entry.Path, err))
-			continue
-		}
+				if ctx.Err() != nil {
+					record(false, false, fmt.Errorf("%s: %w", entry.Path, ctx.Err()))
+					continue
+				}
 
-		// Extract using streaming
-		if err := a.extractFileStreaming(f, entry, outPath, buf); err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", entry.Path, err))
-			continue
-		}
+				if entry.Offset < 0 || entry.Length <= 0 {
+					record(false, false, fmt.Errorf("%s: invalid offset/length", entry.Path))
+					continue
+				}
+				if entry.Offset+entry.Length > archiveSize {
+					record(false, false, fmt.Errorf("%s: entry extends beyond archive", entry.Path))
+					continue
+				}
+
+				outPath, err := secureJoin(destDir, entry.Path)
+				if err != nil {
+					record(false, false, fmt.Errorf("%s: %w", entry.Path, err))
+					continue
+				}
+
+				if _, err := os.Stat(outPath); err == nil {
+					if opts.OnFileDone != nil {
+						opts.OnFileDone(entry, nil)
+					}
+					record(false, true, nil)
+					continue
+				}
+
+				if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+					record(false, false, fmt.Errorf("%s: %w", entry.Path, err))
+					continue
+				}
 
-		result.Extracted++
+				if opts.OnFileStart != nil {
+					opts.OnFileStart(entry, j.index, len(entries))
+				}
+
+				extractErr := a.extractFileStreamingCtx(ctx, f, entry, outPath, buf, opts.OnFileProgress)
+				if opts.OnFileDone != nil {
+					opts.OnFileDone(entry, extractErr)
+				}
+				if extractErr != nil {
+					record(false, false, fmt.Errorf("%s: %w", entry.Path, extractErr))
+					continue
+				}
+
+				record(true, false, nil)
+			}
+		}()
 	}
 
+	for i, entry := range entries {
+		jobs <- job{index: i, entry: entry}
+	}
+	close(jobs)
+	wg.Wait()
+
 	return result, nil
 }
 
-// extractFileStreaming extracts a single file using streaming to minimize memory usage.
-func (a *Archive) extractFileStreaming(src *os.File, entry FileEntry, destPath string, buf []byte) error {
+// extractFileStreamingCtx extracts a single file using streaming to minimize
+// memory usage, checking ctx between reads and reporting progress via
+// onProgress if non-nil.
+func (a *Archive) extractFileStreamingCtx(ctx context.Context, src *os.File, entry FileEntry, destPath string, buf []byte, onProgress func(entry FileEntry, bytesDone, bytesTotal int64)) error {
 	// Seek to file position
 	if _, err := src.Seek(entry.Offset, io.SeekStart); err != nil {
 		return fmt.Errorf("seek error: %w", err)
@@ -449,8 +492,13 @@ func (a *Archive) extractFileStreaming(src *os.File, entry FileEntry, destPath s
 	if len(entry.Prefix) > 0 {
 		remaining -= int64(len(entry.Prefix))
 	}
+	done := entry.Length - remaining
 
 	for remaining > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		toRead := int64(len(buf))
 		if toRead > remaining {
 			toRead = remaining
@@ -469,6 +517,10 @@ func (a *Archive) extractFileStreaming(src *os.File, entry FileEntry, destPath s
 		}
 
 		remaining -= int64(n)
+		done += int64(n)
+		if onProgress != nil {
+			onProgress(entry, done, entry.Length)
+		}
 	}
 
 	return nil
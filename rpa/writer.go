@@ -0,0 +1,229 @@
+package rpa
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/unren/unren-go/rpa/pickle"
+)
+
+// Writer builds an RPA-2.0 or RPA-3.0 archive, mirroring the style of
+// archive/tar and archive/zip: Create returns a writer for one file's data,
+// and Close finalizes the index. Files must be written in full before the
+// next call to Create.
+type Writer struct {
+	w       io.WriteSeeker
+	version int
+	key     uint64
+
+	headerLen int64
+	offset    int64 // current write position, relative to the start of w
+
+	entries []FileEntry
+	cur     *entryWriter
+	closed  bool
+}
+
+// entryWriter streams one file's data into the archive and reports how
+// much it wrote so Writer can record the file's FileEntry on the next
+// Create or on Close.
+type entryWriter struct {
+	w      *Writer
+	path   string
+	prefix []byte
+	start  int64
+	n      int64
+}
+
+func (ew *entryWriter) Write(p []byte) (int, error) {
+	n, err := ew.w.w.Write(p)
+	ew.n += int64(n)
+	ew.w.offset += int64(n)
+	return n, err
+}
+
+// NewWriter prepares w to receive a new RPA archive of the given version (2
+// or 3), writing a placeholder header line that Close rewrites once the
+// real index offset is known. key is ignored for version 2.
+func NewWriter(w io.WriteSeeker, version int, key uint64) (*Writer, error) {
+	if version != 2 && version != 3 {
+		return nil, fmt.Errorf("rpa: unsupported writer version %d", version)
+	}
+
+	wr := &Writer{w: w, version: version, key: key}
+
+	header := wr.headerLine(0)
+	n, err := w.Write([]byte(header))
+	if err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+	wr.headerLen = int64(n)
+	wr.offset = wr.headerLen
+
+	return wr, nil
+}
+
+// headerLine formats the leading header line for indexOffset. %016x and
+// %08x are fixed-width, so rewriting it later in place never changes its
+// length.
+func (w *Writer) headerLine(indexOffset int64) string {
+	if w.version == 3 {
+		return fmt.Sprintf("RPA-3.0 %016x %08x\n", indexOffset, w.key)
+	}
+	return fmt.Sprintf("RPA-2.0 %016x\n", indexOffset)
+}
+
+// Create finalizes the previous entry, if any, and returns a writer for
+// name's content. prefix is recorded in the index only - matching Ren'Py's
+// own RPA format, it is never written into the archive body itself, so
+// Offset always points at the start of the actual content. The returned
+// writer is valid until the next call to Create or Close.
+func (w *Writer) Create(name string, prefix []byte) (io.Writer, error) {
+	if w.closed {
+		return nil, fmt.Errorf("rpa: Create called after Close")
+	}
+	if err := w.finalizeCurrent(); err != nil {
+		return nil, err
+	}
+
+	w.cur = &entryWriter{w: w, path: name, prefix: prefix, start: w.offset}
+	return w.cur, nil
+}
+
+// finalizeCurrent records the FileEntry for the in-progress Create call, if
+// any, applying the RPA-3.0 XOR key the same way ReadIndex removes it.
+func (w *Writer) finalizeCurrent() error {
+	if w.cur == nil {
+		return nil
+	}
+
+	length := int64(len(w.cur.prefix)) + w.cur.n
+	w.entries = append(w.entries, FileEntry{
+		Path:   w.cur.path,
+		Offset: w.cur.start,
+		Length: length,
+		Prefix: w.cur.prefix,
+	})
+	w.cur = nil
+	return nil
+}
+
+// Close finalizes the last entry, pickles the index, zlib-compresses and
+// writes it at the current offset, and rewrites the header line to point
+// at it.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if err := w.finalizeCurrent(); err != nil {
+		return err
+	}
+
+	indexOffset := w.offset
+
+	dict := make(pickle.Dict, len(w.entries))
+	for _, e := range w.entries {
+		tup := pickle.Tuple{w.obfuscate(e.Offset), w.obfuscate(e.Length)}
+		if len(e.Prefix) > 0 {
+			tup = append(tup, string(e.Prefix))
+		}
+		dict[e.Path] = []interface{}{tup}
+	}
+
+	var raw bytes.Buffer
+	if err := pickle.Dump(&raw, dict); err != nil {
+		return fmt.Errorf("failed to pickle index: %w", err)
+	}
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(raw.Bytes()); err != nil {
+		return fmt.Errorf("failed to compress index: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress index: %w", err)
+	}
+
+	if _, err := w.w.Write(compressed.Bytes()); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+
+	if _, err := w.w.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek to header: %w", err)
+	}
+	if _, err := w.w.Write([]byte(w.headerLine(indexOffset))); err != nil {
+		return fmt.Errorf("failed to rewrite header: %w", err)
+	}
+
+	return nil
+}
+
+// obfuscate applies the RPA-3.0 key to an offset/length value, the inverse
+// of Archive.deobfuscate.
+func (w *Writer) obfuscate(val int64) int64 {
+	if w.version == 3 && w.key != 0 {
+		return val ^ int64(w.key)
+	}
+	return val
+}
+
+// Repack streams the entries of src for which filter returns true into a
+// new archive at dstPath, without decompressing file data - only the index
+// is re-pickled. filter may be nil to keep every entry.
+func Repack(srcPath, dstPath string, filter func(FileEntry) bool) error {
+	src, err := Open(srcPath)
+	if err != nil {
+		return err
+	}
+
+	entries, err := src.ReadIndex()
+	if err != nil {
+		return err
+	}
+
+	srcFile, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open source archive: %w", err)
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.Create(dstPath)
+	if err != nil {
+		return fmt.Errorf("failed to create destination archive: %w", err)
+	}
+	defer dstFile.Close()
+
+	w, err := NewWriter(dstFile, src.version, src.key)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if filter != nil && !filter(e) {
+			continue
+		}
+
+		// e.Offset already points at the content, not the prefix - see Create.
+		contentOffset := e.Offset
+		contentLength := e.Length - int64(len(e.Prefix))
+
+		if _, err := srcFile.Seek(contentOffset, io.SeekStart); err != nil {
+			return fmt.Errorf("%s: seek error: %w", e.Path, err)
+		}
+
+		dst, err := w.Create(e.Path, e.Prefix)
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.Path, err)
+		}
+		if _, err := io.CopyN(dst, srcFile, contentLength); err != nil {
+			return fmt.Errorf("%s: copy error: %w", e.Path, err)
+		}
+	}
+
+	return w.Close()
+}
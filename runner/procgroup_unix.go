@@ -0,0 +1,37 @@
+//go:build !windows
+
+package runner
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup puts cmd's process in its own process group (rather than
+// the CLI's) before it starts, so killProcessGroup can signal the whole
+// tree a hung interpreter spawned instead of only the direct child.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// afterProcessStart is a no-op on Unix: setProcessGroup already configured
+// the process group before Start, unlike the Windows job-object approach
+// which needs a live process handle.
+func afterProcessStart(cmd *exec.Cmd) {}
+
+// killProcessGroup sends SIGKILL to cmd's entire process group, so the
+// interpreter's own children die along with it.
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// releaseProcessGroup is a no-op on Unix: a process group has no handle or
+// tracking entry that needs releasing once cmd has exited on its own,
+// unlike the Windows job-object approach.
+func releaseProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,147 @@
+//go:build windows
+
+package runner
+
+import (
+	"os/exec"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// This repo has no third-party dependencies (no golang.org/x/sys), so the
+// job-object calls below go straight through the stdlib syscall package's
+// lazy DLL loading rather than pulling one in just for this.
+var (
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procCreateJobObjectW         = kernel32.NewProc("CreateJobObjectW")
+	procSetInformationJobObject  = kernel32.NewProc("SetInformationJobObject")
+	procAssignProcessToJobObject = kernel32.NewProc("AssignProcessToJobObject")
+	procTerminateJobObject       = kernel32.NewProc("TerminateJobObject")
+)
+
+const (
+	jobObjectExtendedLimitInformation = 9
+	jobObjectLimitKillOnJobClose      = 0x00002000
+
+	// processAccessForJobAssign is the Win32 access mask AssignProcessToJobObject
+	// actually needs (PROCESS_TERMINATE | PROCESS_SET_QUOTA | PROCESS_QUERY_INFORMATION).
+	// stdlib syscall defines PROCESS_TERMINATE and PROCESS_QUERY_INFORMATION but not
+	// PROCESS_SET_QUOTA (that, and PROCESS_ALL_ACCESS, only live in
+	// golang.org/x/sys/windows, which this repo has no dependency on), so the missing
+	// bit is spelled out as its literal Win32 value (0x0100) instead.
+	processAccessForJobAssign = syscall.PROCESS_TERMINATE | 0x0100 | syscall.PROCESS_QUERY_INFORMATION
+)
+
+// jobObjectBasicLimitInformation mirrors the Win32 JOBOBJECT_BASIC_LIMIT_INFORMATION struct.
+type jobObjectBasicLimitInformation struct {
+	PerProcessUserTimeLimit int64
+	PerJobUserTimeLimit     int64
+	LimitFlags              uint32
+	MinimumWorkingSetSize   uintptr
+	MaximumWorkingSetSize   uintptr
+	ActiveProcessLimit      uint32
+	Affinity                uintptr
+	PriorityClass           uint32
+	SchedulingClass         uint32
+}
+
+// ioCounters mirrors the Win32 IO_COUNTERS struct embedded in the extended
+// limit information; its fields are unused here but must be present for the
+// struct layout SetInformationJobObject expects to match.
+type ioCounters struct {
+	ReadOperationCount  uint64
+	WriteOperationCount uint64
+	OtherOperationCount uint64
+	ReadTransferCount   uint64
+	WriteTransferCount  uint64
+	OtherTransferCount  uint64
+}
+
+// jobObjectExtendedLimitInfo mirrors the Win32
+// JOBOBJECT_EXTENDED_LIMIT_INFORMATION struct.
+type jobObjectExtendedLimitInfo struct {
+	BasicLimitInformation jobObjectBasicLimitInformation
+	IoInfo                ioCounters
+	ProcessMemoryLimit    uintptr
+	JobMemoryLimit        uintptr
+	PeakProcessMemoryUsed uintptr
+	PeakJobMemoryUsed     uintptr
+}
+
+// cmdJobs tracks the kill-on-close job object each tracked *exec.Cmd's
+// process was assigned to, so killProcessGroup can look it up and terminate
+// it - and everything it spawned - instead of only the direct child.
+var cmdJobs sync.Map // *exec.Cmd -> syscall.Handle
+
+// setProcessGroup is a no-op on Windows: unlike Unix's Setpgid, assigning a
+// process to a job object requires a live process handle, so the real work
+// happens in afterProcessStart once cmd has actually started.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// afterProcessStart creates a kill-on-close job object and assigns cmd's
+// just-started process to it, so killProcessGroup can terminate the whole
+// tree a hung interpreter spawned, not just the direct child.
+func afterProcessStart(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+
+	job, _, _ := procCreateJobObjectW.Call(0, 0)
+	if job == 0 {
+		return
+	}
+	handle := syscall.Handle(job)
+
+	info := jobObjectExtendedLimitInfo{
+		BasicLimitInformation: jobObjectBasicLimitInformation{
+			LimitFlags: jobObjectLimitKillOnJobClose,
+		},
+	}
+	procSetInformationJobObject.Call(
+		uintptr(handle),
+		jobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)),
+		unsafe.Sizeof(info),
+	)
+
+	procHandle, err := syscall.OpenProcess(processAccessForJobAssign, false, uint32(cmd.Process.Pid))
+	if err != nil {
+		syscall.CloseHandle(handle)
+		return
+	}
+	procAssignProcessToJobObject.Call(uintptr(handle), uintptr(procHandle))
+	syscall.CloseHandle(procHandle)
+
+	cmdJobs.Store(cmd, handle)
+}
+
+// killProcessGroup terminates the job object cmd's process was assigned to
+// in afterProcessStart, which kills it and every child process it spawned.
+// Falls back to killing just the direct process if no job object was
+// tracked for it (e.g. afterProcessStart failed to create one).
+func killProcessGroup(cmd *exec.Cmd) {
+	v, ok := cmdJobs.LoadAndDelete(cmd)
+	if !ok {
+		if cmd.Process != nil {
+			cmd.Process.Kill()
+		}
+		return
+	}
+	handle := v.(syscall.Handle)
+	procTerminateJobObject.Call(uintptr(handle), 1)
+	syscall.CloseHandle(handle)
+}
+
+// releaseProcessGroup closes the job object handle cmd's process was
+// assigned to in afterProcessStart, for the normal-completion path where
+// killProcessGroup is never called. Without this, both the Win32 handle and
+// the cmdJobs entry leak - one of each per file - across a long batch,
+// since killProcessGroup only runs on timeout/cancellation.
+func releaseProcessGroup(cmd *exec.Cmd) {
+	v, ok := cmdJobs.LoadAndDelete(cmd)
+	if !ok {
+		return
+	}
+	syscall.CloseHandle(v.(syscall.Handle))
+}
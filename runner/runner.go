@@ -3,12 +3,17 @@
 package runner
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/unren/unren-go/detector"
 	"github.com/unren/unren-go/files"
@@ -21,12 +26,82 @@ type Runner struct {
 	PythonExe string
 	PythonLib string
 	IsPython3 bool
+
+	// KeepTemp, if true, leaves the extracted helper scripts (_rpatool.py,
+	// unrpyc.py, decompiler/, ...) on disk after Cleanup instead of removing
+	// them, and steers SetupTempDir away from the game root (see TempBase)
+	// so debugging a failed run doesn't mean permanently littering the
+	// user's game directory.
+	KeepTemp bool
+
+	// TempBase, if set, is the directory SetupTempDir writes helper scripts
+	// into instead of the game root. If empty and KeepTemp is true,
+	// SetupTempDir creates one via os.MkdirTemp instead.
+	TempBase string
+
+	// PerFileTimeout, if greater than zero, bounds how long any single
+	// interpreter subprocess (one ExtractRPA/DecompileRPYC call) may run
+	// before the *Context methods kill it and record it as failed, so one
+	// malformed .rpyc can't hang a whole batch.
+	PerFileTimeout time.Duration
+
+	// AllowSystemPython, if true, lets findPython fall back to a python3 or
+	// python2 found on PATH when the game ships no usable bundled
+	// interpreter (unsupported OS/arch, or a launcher stub with no lib/ at
+	// all), instead of failing outright.
+	AllowSystemPython bool
+
+	// SuppressChildStdout, if true, discards the Python subprocess's stdout
+	// instead of inheriting the process's real os.Stdout. The embedded
+	// rpatool/unrpyc scripts print diagnostic chatter there, which corrupts
+	// a structured --format json/ndjson run, so the reported-mode call path
+	// (see report_actions.go) sets this once it has chosen a non-text
+	// Reporter. Stderr is unaffected - it's still inherited (and, where
+	// recorded, captured into the RunEntry's StderrTail) either way.
+	SuppressChildStdout bool
+
+	// PythonPathOverride, if non-empty, is used by findPython in place of
+	// the bundled-interpreter search (and the AllowSystemPython fallback)
+	// entirely, for a user who wants to point unren at a specific
+	// interpreter - see config.Overrides.PythonPath. Set via NewRunner, not
+	// assigned after the fact, since findPython runs during construction.
+	PythonPathOverride string
+
+	// interpreterSource records which interpreter findPython resolved to -
+	// "bundled", "system:<path>", or "override:<path>" - so RunEntry can
+	// surface it.
+	interpreterSource string
+
+	// report accumulates the RunReport entries for this Runner and every
+	// Runner Clone() derives from it, so concurrent runParallel (see
+	// parallel.go) workers all append to the same report.
+	report *runReport
+
+	// unrpyc guards SetupUnrpyc's decompiler extraction so it only runs
+	// once across a Runner and every Clone() derived from it, instead of
+	// every concurrent per-file worker (see parallel.go's runParallel)
+	// re-extracting - and so racing each other's os.WriteFile truncation
+	// of - the same unrpyc.py/decompiler/*.py while siblings' python
+	// subprocesses are reading them.
+	unrpyc *unrpycState
+}
+
+// unrpycState is the shared, once-only outcome of SetupUnrpyc's decompiler
+// extraction; see Runner.unrpyc.
+type unrpycState struct {
+	once sync.Once
+	err  error
 }
 
-// NewRunner creates a new runner for the detected game.
-func NewRunner(gameInfo *detector.GameInfo) (*Runner, error) {
+// NewRunner creates a new runner for the detected game. pythonPathOverride,
+// if non-empty (from config.Overrides.PythonPath), is used in place of the
+// bundled-interpreter search entirely - see findPython.
+func NewRunner(gameInfo *detector.GameInfo, pythonPathOverride string) (*Runner, error) {
 	r := &Runner{
-		GameInfo: gameInfo,
+		GameInfo:           gameInfo,
+		report:             &runReport{},
+		unrpyc:             &unrpycState{},
+		PythonPathOverride: pythonPathOverride,
 	}
 
 	// Find Python executable
@@ -41,15 +116,53 @@ func NewRunner(gameInfo *detector.GameInfo) (*Runner, error) {
 	return r, nil
 }
 
-// findPython locates the Python interpreter bundled with the game.
+// Clone returns a copy of the Runner that shares the same resolved Python
+// interpreter and TempDir. Call SetupTempDir/SetupUnrpyc once on the
+// original before cloning; the clone's exported methods only read that
+// state, so each clone can safely drive concurrent extraction/decompilation
+// jobs from its own goroutine without re-running setup.
+func (r *Runner) Clone() *Runner {
+	clone := *r
+	return &clone
+}
+
+// findPython locates the Python interpreter bundled with the game, falling
+// back to a system interpreter (see findSystemPython) if AllowSystemPython
+// is set and nothing bundled is usable. If PythonPathOverride is set, it
+// short-circuits all of that and is used directly. Sets r.interpreterSource
+// to "override:<path>", "bundled", or "system:<path>" so RunEntry can
+// surface which one was used.
 // Returns: pythonExe path, pythonLib path, isPython3, error
 func (r *Runner) findPython() (string, string, bool, error) {
+	if r.PythonPathOverride != "" {
+		if _, err := os.Stat(r.PythonPathOverride); err != nil {
+			return "", "", false, fmt.Errorf("configured python interpreter not found: %w", err)
+		}
+		pythonLib := r.findPythonLib(filepath.Dir(r.PythonPathOverride))
+		lower := strings.ToLower(r.PythonPathOverride)
+		isPy3 := !strings.Contains(lower, "py2") && !strings.Contains(lower, "python2")
+		r.interpreterSource = "override:" + r.PythonPathOverride
+		return r.PythonPathOverride, pythonLib, isPy3, nil
+	}
+
 	if r.GameInfo.RootDir == "" {
 		return "", "", false, fmt.Errorf("game root directory not set")
 	}
 
 	libDir := filepath.Join(r.GameInfo.RootDir, "lib")
 	if _, err := os.Stat(libDir); os.IsNotExist(err) {
+		// Ren'Py's macOS export is an app bundle; the lib/ directory lives
+		// under Contents/MacOS instead of directly at the game root.
+		if bundleLibDir := filepath.Join(r.GameInfo.RootDir, "Contents", "MacOS", "lib"); runtime.GOOS == "darwin" {
+			if _, err := os.Stat(bundleLibDir); err == nil {
+				libDir = bundleLibDir
+			}
+		}
+	}
+	if _, err := os.Stat(libDir); os.IsNotExist(err) {
+		if r.AllowSystemPython {
+			return r.findSystemPython()
+		}
 		return "", "", false, fmt.Errorf("lib directory not found at %s", libDir)
 	}
 
@@ -66,36 +179,61 @@ func (r *Runner) findPython() (string, string, bool, error) {
 		osPrefix = "linux"
 	}
 
-	bitness := "x86_64"
-	if runtime.GOARCH != "amd64" {
-		bitness = "i686"
+	// Ren'Py's arm64 macOS/Linux builds are seen under both "arm64" and
+	// "aarch64", so try both rather than picking one.
+	var bitnesses []string
+	switch runtime.GOARCH {
+	case "amd64":
+		bitnesses = []string{"x86_64"}
+	case "386":
+		bitnesses = []string{"i686"}
+	case "arm64":
+		bitnesses = []string{"arm64", "aarch64"}
+	default:
+		bitnesses = []string{"x86_64"}
 	}
 
 	// Try Python 3 paths first (Ren'Py 8)
-	py3Patterns := []string{
-		filepath.Join(libDir, fmt.Sprintf("py3-%s-%s", osPrefix, bitness)),
-		filepath.Join(libDir, fmt.Sprintf("python3-%s-%s", osPrefix, bitness)),
+	var py3Patterns []string
+	for _, bitness := range bitnesses {
+		py3Patterns = append(py3Patterns,
+			filepath.Join(libDir, fmt.Sprintf("py3-%s-%s", osPrefix, bitness)),
+			filepath.Join(libDir, fmt.Sprintf("python3-%s-%s", osPrefix, bitness)),
+		)
+	}
+	// Some Ren'Py 8 exports (notably web/Android-adjacent layouts) drop the
+	// os-bitness suffix entirely and ship a bare lib/python3.X directory.
+	if matches, err := filepath.Glob(filepath.Join(libDir, "python3*")); err == nil {
+		py3Patterns = append(py3Patterns, matches...)
 	}
 
 	for _, pyDir := range py3Patterns {
 		pythonExe := r.getPythonExe(pyDir)
 		if pythonExe != "" {
 			pythonLib := r.findPythonLib(pyDir)
+			r.interpreterSource = "bundled"
 			return pythonExe, pythonLib, true, nil
 		}
 	}
 
 	// Try Python 2 paths (Ren'Py 7)
-	py2Patterns := []string{
-		filepath.Join(libDir, fmt.Sprintf("py2-%s-%s", osPrefix, bitness)),
-		filepath.Join(libDir, fmt.Sprintf("python2-%s-%s", osPrefix, bitness)),
-		filepath.Join(libDir, fmt.Sprintf("%s-%s", osPrefix, bitness)), // Legacy
+	var py2Patterns []string
+	for _, bitness := range bitnesses {
+		py2Patterns = append(py2Patterns,
+			filepath.Join(libDir, fmt.Sprintf("py2-%s-%s", osPrefix, bitness)),
+			filepath.Join(libDir, fmt.Sprintf("python2-%s-%s", osPrefix, bitness)),
+			filepath.Join(libDir, fmt.Sprintf("%s-%s", osPrefix, bitness)), // Legacy
+		)
+	}
+	if matches, err := filepath.Glob(filepath.Join(libDir, "python2*")); err == nil {
+		py2Patterns = append(py2Patterns, matches...)
 	}
 
 	for _, pyDir := range py2Patterns {
 		pythonExe := r.getPythonExe(pyDir)
 		if pythonExe != "" {
 			pythonLib := r.findPythonLib(pyDir)
+			r.interpreterSource = "bundled"
 			return pythonExe, pythonLib, false, nil
 		}
 	}
@@ -103,12 +241,41 @@ func (r *Runner) findPython() (string, string, bool, error) {
 	// Fallback: search recursively
 	pythonExe, pythonLib, isPy3 := r.searchForPython(libDir)
 	if pythonExe != "" {
+		r.interpreterSource = "bundled"
 		return pythonExe, pythonLib, isPy3, nil
 	}
 
+	if r.AllowSystemPython {
+		return r.findSystemPython()
+	}
+
 	return "", "", false, fmt.Errorf("could not find Python executable in %s", libDir)
 }
 
+// findSystemPython looks for a python3, then python2, interpreter on PATH,
+// for games that ship no usable bundled interpreter at all (e.g. a launcher
+// stub, or an unsupported OS/arch combination). Only called when
+// AllowSystemPython is set. getPythonEnv skips the PYTHONHOME override for
+// whichever one is found, since overriding it would break the host Python
+// install rather than point at a game-bundled one.
+func (r *Runner) findSystemPython() (string, string, bool, error) {
+	for _, candidate := range []struct {
+		name  string
+		isPy3 bool
+	}{
+		{"python3", true},
+		{"python2", false},
+	} {
+		path, err := exec.LookPath(candidate.name)
+		if err != nil {
+			continue
+		}
+		r.interpreterSource = "system:" + path
+		return path, "", candidate.isPy3, nil
+	}
+	return "", "", false, fmt.Errorf("no bundled Python found, and no system python3/python2 on PATH")
+}
+
 // getPythonExe returns the python executable path if it exists.
 func (r *Runner) getPythonExe(pyDir string) string {
 	var exeName string
@@ -193,10 +360,27 @@ func (r *Runner) findPythonLib(pythonHome string) string {
 
 // SetupTempDir creates a temporary directory and extracts scripts.
 // SetupTempDir creates a temporary directory and extracts scripts.
-// The batch script uses the game root directory (maindir) for these scripts.
+// The batch script uses the game root directory (maindir) for these scripts;
+// that remains the default here, but TempBase/KeepTemp can steer it
+// elsewhere - see their doc comments on Runner.
 func (r *Runner) SetupTempDir() error {
-	// Use game root directory
-	r.TempDir = r.GameInfo.RootDir
+	switch {
+	case r.TempDir != "":
+		// Already set up by a previous call.
+	case r.TempBase != "":
+		if err := os.MkdirAll(r.TempBase, 0755); err != nil {
+			return fmt.Errorf("failed to create temp base %s: %w", r.TempBase, err)
+		}
+		r.TempDir = r.TempBase
+	case r.KeepTemp:
+		dir, err := os.MkdirTemp("", "unren-*")
+		if err != nil {
+			return fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		r.TempDir = dir
+	default:
+		r.TempDir = r.GameInfo.RootDir
+	}
 
 	// Extract rpatool to _rpatool.py (matching batch script)
 	rpatool, err := files.GetRPATool(r.IsPython3)
@@ -219,8 +403,11 @@ func (r *Runner) SetupTempDir() error {
 	return nil
 }
 
-// SetupUnrpyc extracts the unrpyc decompiler.
-// SetupUnrpyc extracts the unrpyc decompiler.
+// SetupUnrpyc extracts the unrpyc decompiler. Safe to call from concurrent
+// Clone()s of the same Runner (see parallel.go's runParallel): the actual
+// extraction happens at most once, guarded by r.unrpyc, so concurrent
+// workers never race each other's os.WriteFile of the same decompiler
+// files.
 func (r *Runner) SetupUnrpyc() error {
 	if r.TempDir == "" {
 		if err := r.SetupTempDir(); err != nil {
@@ -228,7 +415,14 @@ func (r *Runner) SetupUnrpyc() error {
 		}
 	}
 
-	return files.ExtractUnrpyc(r.TempDir, r.IsPython3)
+	if r.unrpyc == nil {
+		return files.ExtractUnrpyc(r.TempDir, r.IsPython3)
+	}
+
+	r.unrpyc.once.Do(func() {
+		r.unrpyc.err = files.ExtractUnrpyc(r.TempDir, r.IsPython3)
+	})
+	return r.unrpyc.err
 }
 
 // Cleanup removes the temporary directory.
@@ -238,6 +432,24 @@ func (r *Runner) Cleanup() {
 		return
 	}
 
+	if r.KeepTemp {
+		out := io.Writer(os.Stdout)
+		if r.SuppressChildStdout {
+			out = os.Stderr
+		}
+		fmt.Fprintf(out, "    Keeping temp directory for inspection: %s\n", r.TempDir)
+		return
+	}
+
+	if r.TempDir != r.GameInfo.RootDir && r.TempDir != r.TempBase {
+		// TempDir is a scratch directory SetupTempDir created itself via
+		// os.MkdirTemp, not the live game directory, so it's safe to remove
+		// wholesale. A TempBase may be an existing directory, so it gets the
+		// same per-file cleanup as the game root below.
+		os.RemoveAll(r.TempDir)
+		return
+	}
+
 	files := []string{
 		"_rpatool.py",
 		"_rpa.py",
@@ -261,16 +473,26 @@ func (r *Runner) Cleanup() {
 // getPythonEnv returns the environment variables for Python execution.
 // getPythonEnv returns the environment variables for Python execution.
 func (r *Runner) getPythonEnv() []string {
-	pythonHome := filepath.Dir(r.PythonExe)
-
 	env := os.Environ()
-	env = append(env, fmt.Sprintf("PYTHONHOME=%s", pythonHome))
+	// A system interpreter, or a PythonPathOverride that doesn't point into
+	// a Ren'Py-style lib/ layout (no encodings/ dir found next to it, so
+	// PythonLib is empty), already knows its own home; overriding
+	// PYTHONHOME would point it at a bundled stdlib (or nothing) instead of
+	// its own, breaking it.
+	isSystemPython := strings.HasPrefix(r.interpreterSource, "system:") ||
+		(strings.HasPrefix(r.interpreterSource, "override:") && r.PythonLib == "")
 
 	// Build PYTHONPATH
 	// Batch script sets: PYTHONPATH=%pythondir%;%pythonlibdir%;%maindir%;%decompilerdir%\
-	paths := []string{pythonHome}
-	if r.PythonLib != "" {
-		paths = append(paths, r.PythonLib)
+	var paths []string
+	if isSystemPython {
+	} else {
+		pythonHome := filepath.Dir(r.PythonExe)
+		env = append(env, fmt.Sprintf("PYTHONHOME=%s", pythonHome))
+		paths = append(paths, pythonHome)
+		if r.PythonLib != "" {
+			paths = append(paths, r.PythonLib)
+		}
 	}
 	// Add game root (maindir) and decompiler dir
 	if r.TempDir != "" {
@@ -288,8 +510,27 @@ func (r *Runner) getPythonEnv() []string {
 	return env
 }
 
-// ExtractRPA extracts an RPA archive using the embedded rpatool.
+// childStdout returns os.Stdout, or io.Discard if SuppressChildStdout is
+// set - see its doc comment.
+func (r *Runner) childStdout() io.Writer {
+	if r.SuppressChildStdout {
+		return io.Discard
+	}
+	return os.Stdout
+}
+
+// ExtractRPA extracts an RPA archive using the embedded rpatool. It never
+// times out; use ExtractRPAContext to bound it with PerFileTimeout or a
+// cancellable ctx.
 func (r *Runner) ExtractRPA(rpaPath string) error {
+	return r.ExtractRPAContext(context.Background(), rpaPath)
+}
+
+// ExtractRPAContext is ExtractRPA built on exec.CommandContext: cancelling
+// ctx, or letting PerFileTimeout elapse, kills the interpreter's whole
+// process group (see runContext) instead of leaving a hung subprocess
+// behind, and the failure is recorded in the report like any other error.
+func (r *Runner) ExtractRPAContext(ctx context.Context, rpaPath string) error {
 	if r.TempDir == "" {
 		if err := r.SetupTempDir(); err != nil {
 			return err
@@ -301,32 +542,63 @@ func (r *Runner) ExtractRPA(rpaPath string) error {
 	outputDir := filepath.Dir(rpaPath)
 	env := r.getPythonEnv()
 
+	start := time.Now()
+	tool := "rpatool"
+	var stderrBuf bytes.Buffer
+
 	// First try rpatool.py with extract flag
-	cmd := exec.Command(r.PythonExe, "-O", rpatoolPath, "-x", rpaPath, "-o", outputDir)
+	cmd := exec.CommandContext(ctx, r.PythonExe, "-O", rpatoolPath, "-x", rpaPath, "-o", outputDir)
 	cmd.Dir = outputDir
 	cmd.Env = env
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
-		// Fallback to rpa.py (simpler extractor)
+	cmd.Stdout = r.childStdout()
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderrBuf)
+
+	jobCtx, runErr := r.runContext(ctx, cmd)
+	// runContext cancels its per-file timeout context before returning, so
+	// only DeadlineExceeded on jobCtx marks a timeout.
+	if runErr != nil && ctx.Err() == nil && jobCtx.Err() != context.DeadlineExceeded {
+		// Fallback to rpa.py (simpler extractor), but only if the first
+		// attempt failed on its own merits - not because the file timed out
+		// or the run was cancelled, in which case retrying is pointless.
 		fmt.Println("    Retrying with fallback extractor...")
-		cmd = exec.Command(r.PythonExe, rpaFallbackPath, rpaPath)
+		tool = "rpa.py"
+		stderrBuf.Reset()
+
+		cmd = exec.CommandContext(ctx, r.PythonExe, rpaFallbackPath, rpaPath)
 		cmd.Dir = outputDir
 		cmd.Env = env
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		cmd.Stdout = r.childStdout()
+		cmd.Stderr = io.MultiWriter(os.Stderr, &stderrBuf)
 
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to extract RPA: %w", err)
-		}
+		_, runErr = r.runContext(ctx, cmd)
+	}
+
+	r.recordRun(RunEntry{
+		Tool:        tool,
+		InputPath:   rpaPath,
+		OutputPath:  outputDir,
+		Duration:    time.Since(start),
+		Status:      statusFor(runErr),
+		Interpreter: r.interpreterSource,
+		StderrTail:  tailLines(stderrBuf.String(), 20),
+	})
+
+	if runErr != nil {
+		return fmt.Errorf("failed to extract RPA: %w", runErr)
 	}
 
 	return nil
 }
 
-// DecompileRPYC decompiles an RPYC file to RPY.
+// DecompileRPYC decompiles an RPYC file to RPY. It never times out; use
+// DecompileRPYCContext to bound it with PerFileTimeout or a cancellable ctx.
 func (r *Runner) DecompileRPYC(rpycPath string) error {
+	return r.DecompileRPYCContext(context.Background(), rpycPath)
+}
+
+// DecompileRPYCContext is DecompileRPYC built on exec.CommandContext; see
+// ExtractRPAContext for how timeout/cancellation is enforced.
+func (r *Runner) DecompileRPYCContext(ctx context.Context, rpycPath string) error {
 	if err := r.SetupUnrpyc(); err != nil {
 		return fmt.Errorf("failed to setup unrpyc: %w", err)
 	}
@@ -340,100 +612,148 @@ func (r *Runner) DecompileRPYC(rpycPath string) error {
 	}
 	args = append(args, rpycPath)
 
-	cmd := exec.Command(r.PythonExe, args...)
+	tool := "unrpyc-py2"
+	if r.IsPython3 {
+		tool = "unrpyc-py3"
+	}
+
+	start := time.Now()
+	var stderrBuf bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, r.PythonExe, args...)
 	cmd.Dir = filepath.Dir(rpycPath)
 	cmd.Env = env
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = r.childStdout()
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderrBuf)
+
+	_, runErr := r.runContext(ctx, cmd)
+
+	r.recordRun(RunEntry{
+		Tool:        tool,
+		InputPath:   rpycPath,
+		Duration:    time.Since(start),
+		Status:      statusFor(runErr),
+		Interpreter: r.interpreterSource,
+		StderrTail:  tailLines(stderrBuf.String(), 20),
+	})
 
-	return cmd.Run()
+	return runErr
 }
 
-// DecompileAllRPYC decompiles all RPYC files in the game directory.
-// Returns: success count, skipped count, failed count, error
-func (r *Runner) DecompileAllRPYC() (int, int, int, error) {
-	if err := r.SetupUnrpyc(); err != nil {
-		return 0, 0, 0, fmt.Errorf("failed to setup unrpyc: %w", err)
+// runContext starts cmd - already built via exec.CommandContext so it
+// inherits ctx's cancellation - inside its own process group/job object
+// (see setProcessGroup/killProcessGroup in procgroup_*.go), additionally
+// bounding it by r.PerFileTimeout when set. If ctx is done before cmd exits
+// on its own, runContext kills the whole process group rather than just the
+// direct child - a hung unrpyc otherwise leaves its own children running -
+// and returns the (possibly timeout-derived) context alongside ctx.Err(). On
+// normal completion it calls releaseProcessGroup, which on Windows closes
+// the job object handle afterProcessStart created (a no-op on Unix, which
+// has no handle to leak) so a long batch doesn't leak one handle per file.
+func (r *Runner) runContext(ctx context.Context, cmd *exec.Cmd) (context.Context, error) {
+	if r.PerFileTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.PerFileTimeout)
+		defer cancel()
 	}
 
-	unrpycPath := filepath.Join(r.TempDir, "unrpyc.py")
-	env := r.getPythonEnv()
-	gameDir := r.GameInfo.GameDir
-
-	success := 0
-	skipped := 0
-	failed := 0
-	lastDir := ""
-
-	err := filepath.Walk(gameDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Continue on errors
-		}
-		if info.IsDir() || !strings.HasSuffix(strings.ToLower(path), ".rpyc") {
-			return nil
-		}
+	setProcessGroup(cmd)
 
-		// Skip un.rpyc (special Ren'Py file)
-		baseName := filepath.Base(path)
-		nameWithoutExt := strings.TrimSuffix(baseName, filepath.Ext(baseName))
-		if strings.ToLower(nameWithoutExt) == "un" {
-			return nil
-		}
+	if err := cmd.Start(); err != nil {
+		return ctx, err
+	}
+	afterProcessStart(cmd)
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		releaseProcessGroup(cmd)
+		return ctx, err
+	case <-ctx.Done():
+		killProcessGroup(cmd)
+		<-done
+		return ctx, ctx.Err()
+	}
+}
 
-		// Show directory change (like batch script)
-		currentDir := filepath.Dir(path)
-		if currentDir != lastDir {
-			lastDir = currentDir
-			relDir, _ := filepath.Rel(r.GameInfo.RootDir, currentDir)
-			if relDir == "" {
-				relDir = "."
-			}
-			fmt.Printf("  Working in: '%s'\n", relDir)
-		}
+// RunEntry is the outcome of one file processed by ExtractRPA or
+// DecompileRPYC: which tool handled it, how long it took, and - on
+// failure - a tail of what it printed.
+type RunEntry struct {
+	// Tool identifies the variant that handled this file: "rpatool" or
+	// "rpa.py" for extraction, "unrpyc-py2" or "unrpyc-py3" for decompilation.
+	Tool       string        `json:"tool"`
+	InputPath  string        `json:"input_path"`
+	OutputPath string        `json:"output_path,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	// Status is "success" or "failed".
+	Status string `json:"status"`
+	// Interpreter is "bundled", "system:<path>", or "override:<path>",
+	// identifying whether this file was processed with the game's own
+	// Python, a fallback found on PATH via AllowSystemPython (see
+	// Runner.findSystemPython), or an explicit PythonPathOverride.
+	Interpreter string `json:"interpreter,omitempty"`
+	StderrTail  string `json:"stderr_tail,omitempty"`
+}
 
-		// Check if rpy already exists
-		rpyPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".rpy"
-		if _, err := os.Stat(rpyPath); err == nil {
-			// Match batch script output: "filename.rpy" already exists - skipped
-			fmt.Printf("    \"%s.rpy\" already exists - skipped\n", nameWithoutExt)
-			skipped++
-			return nil
-		}
+// RunReport is a snapshot of every RunEntry a Runner has recorded so far,
+// serializable via encoding/json for the --json-report CLI flag.
+type RunReport struct {
+	Entries []RunEntry `json:"entries"`
+}
 
-		// Show decompiling message with file size (like batch script)
-		fmt.Printf("    + Decompiling \"%s\" - %d bytes\n", baseName, info.Size())
+// runReport is the mutex-guarded accumulator a Runner and every clone
+// derived from it via Clone share, so concurrent runParallel (see
+// parallel.go) workers can append to the same report without racing.
+type runReport struct {
+	mu      sync.Mutex
+	entries []RunEntry
+}
 
-		args := []string{"-O", unrpycPath}
-		if !r.IsPython3 {
-			args = append(args, "--init-offset")
-		}
-		args = append(args, path)
+func (rr *runReport) add(e RunEntry) {
+	rr.mu.Lock()
+	rr.entries = append(rr.entries, e)
+	rr.mu.Unlock()
+}
 
-		cmd := exec.Command(r.PythonExe, args...)
-		cmd.Dir = filepath.Dir(path)
-		cmd.Env = env
+// recordRun appends e to r's shared report. It is a no-op if r was built
+// without NewRunner and so has no report to append to.
+func (r *Runner) recordRun(e RunEntry) {
+	if r.report == nil {
+		return
+	}
+	r.report.add(e)
+}
 
-		if output, err := cmd.CombinedOutput(); err == nil {
-			// Verify output file was created
-			if _, err := os.Stat(rpyPath); err == nil {
-				success++
-			} else {
-				fmt.Printf("    - Failed to create RPY file: %s.rpy not found.\n", nameWithoutExt)
-				if len(output) > 0 {
-					fmt.Printf("    Output:\n%s\n", string(output))
-				}
-				failed++
-			}
-		} else {
-			fmt.Printf("    - Failed to decompile \"%s\". Error: %v\n", baseName, err)
-			if len(output) > 0 {
-				fmt.Printf("    Output:\n%s\n", string(output))
-			}
-			failed++
-		}
+// Report returns a snapshot of every file outcome ExtractRPA and
+// DecompileRPYC have recorded so far on r and any Runner cloned from it.
+func (r *Runner) Report() *RunReport {
+	if r.report == nil {
+		return &RunReport{}
+	}
+	r.report.mu.Lock()
+	defer r.report.mu.Unlock()
+	entries := make([]RunEntry, len(r.report.entries))
+	copy(entries, r.report.entries)
+	return &RunReport{Entries: entries}
+}
 
-		return nil
-	})
+// statusFor maps a subprocess error to a RunEntry status.
+func statusFor(err error) string {
+	if err != nil {
+		return "failed"
+	}
+	return "success"
+}
 
-	return success, skipped, failed, err
+// tailLines returns the last n lines of s, ignoring trailing newlines, for trimming a
+// subprocess's full output down to a debuggable stderr tail.
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
 }
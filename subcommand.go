@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/unren/unren-go/detector"
+	"github.com/unren/unren-go/patcher"
+)
+
+// subcommands are the verbs handled before the regular flag-based CLI. The
+// status/verify/uninstall/upgrade verbs operate on the unren-manifest.json a
+// previous run left in a game directory rather than re-deriving state from
+// scratch; config reads and writes config files instead.
+var subcommands = map[string]func([]string){
+	"status":    runStatusCommand,
+	"verify":    runVerifyCommand,
+	"uninstall": runUninstallCommand,
+	"upgrade":   runUpgradeCommand,
+	"config":    runConfigCommand,
+}
+
+// isSubcommand reports whether args names one of the status/verify/
+// uninstall/upgrade/config subcommands, so main can dispatch before flag.Parse
+// gets a chance to treat the verb as a positional game directory.
+func isSubcommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	_, ok := subcommands[args[0]]
+	return ok
+}
+
+// runSubcommand dispatches to the handler for args[0].
+func runSubcommand(args []string) {
+	subcommands[args[0]](args[1:])
+}
+
+// subcommandGameDir resolves the optional trailing directory argument of a
+// subcommand's own flag set, defaulting to the current directory.
+func subcommandGameDir(fs *flag.FlagSet) (*detector.GameInfo, error) {
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = strings.Trim(fs.Arg(0), "\"'")
+	}
+	return detector.DetectGame(dir)
+}
+
+// subcommandPatcher builds a *patcher.Patcher for game, honoring any
+// configured hotkeys/template overrides for its directory.
+func subcommandPatcher(game *detector.GameInfo) *patcher.Patcher {
+	return patcher.New(game.GameDir, patcherConfig(game))
+}
+
+func runStatusCommand(args []string) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	fs.Parse(args)
+
+	game, err := subcommandGameDir(fs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  ! %v\n", err)
+		os.Exit(1)
+	}
+
+	statuses, err := subcommandPatcher(game).Status()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  ! %v\n", err)
+		os.Exit(1)
+	}
+	if len(statuses) == 0 {
+		fmt.Println("  No unren patches installed in this game directory.")
+		return
+	}
+
+	fmt.Printf("  %-24s %s\n", "File", "State")
+	for _, s := range statuses {
+		fmt.Printf("  %-24s %s\n", s.RelPath, s.State)
+	}
+}
+
+func runVerifyCommand(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	fs.Parse(args)
+
+	game, err := subcommandGameDir(fs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  ! %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := subcommandPatcher(game).Verify(); err != nil {
+		fmt.Fprintf(os.Stderr, "  ! %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("  OK: all installed patches match what unren wrote.")
+}
+
+func runUninstallCommand(args []string) {
+	fs := flag.NewFlagSet("uninstall", flag.ExitOnError)
+	force := fs.Bool("force", false, "Remove patch files even if they were modified after install")
+	fs.Parse(args)
+
+	game, err := subcommandGameDir(fs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  ! %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := subcommandPatcher(game).RemoveAll(*force); err != nil {
+		fmt.Fprintf(os.Stderr, "  ! %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("  Uninstalled all tracked unren patches.")
+}
+
+func runUpgradeCommand(args []string) {
+	fs := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	fs.Parse(args)
+
+	game, err := subcommandGameDir(fs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  ! %v\n", err)
+		os.Exit(1)
+	}
+
+	upgraded, err := subcommandPatcher(game).Upgrade()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  ! %v\n", err)
+		os.Exit(1)
+	}
+	if len(upgraded) == 0 {
+		fmt.Println("  Nothing to upgrade; all installed patches are current.")
+		return
+	}
+	for _, f := range upgraded {
+		fmt.Printf("  Upgraded %s\n", f)
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -121,6 +122,12 @@ func DirExists(path string) bool {
 
 // FindFilesWithExtension finds all files with a given extension in a directory (recursive)
 func FindFilesWithExtension(dir, ext string) ([]string, error) {
+	return FindFilesWithExtensionCtx(context.Background(), dir, ext)
+}
+
+// FindFilesWithExtensionCtx is FindFilesWithExtension, checking ctx between
+// directory entries so a walk over a large game's files can be cancelled.
+func FindFilesWithExtensionCtx(ctx context.Context, dir, ext string) ([]string, error) {
 	var files []string
 	ext = strings.ToLower(ext)
 	if !strings.HasPrefix(ext, ".") {
@@ -131,6 +138,9 @@ func FindFilesWithExtension(dir, ext string) ([]string, error) {
 		if err != nil {
 			return err
 		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if !info.IsDir() && strings.ToLower(filepath.Ext(path)) == ext {
 			files = append(files, path)
 		}